@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/divolgin/grump"
+)
+
+// dependencyVersion returns the version of a module grump was built with, "unknown" if the
+// build information isn't available
+func dependencyVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
+
+// printMatchers loads the vulnerability database with the run's options and writes what
+// matching would use to w: the grump, grype, and syft versions, the database, and the enabled
+// matchers. This is the information a bug report about a missed finding needs.
+func printMatchers(w io.Writer, opts options) exitStatus {
+	runner, err := grump.NewRunner(opts.Options)
+	if err != nil {
+		slog.Error("Failed to start", "error", err)
+		return statusError
+	}
+	defer runner.Close()
+	scan := runner.Scanner()
+
+	fmt.Fprintf(w, "grump version:    %s\n", opts.Version)
+	fmt.Fprintf(w, "grype version:    %s\n", dependencyVersion("github.com/anchore/grype"))
+	fmt.Fprintf(w, "syft version:     %s\n", dependencyVersion("github.com/anchore/syft"))
+	if built := scan.DBBuilt(); !built.IsZero() {
+		fmt.Fprintf(w, "database built:   %s\n", built.UTC().Format(time.RFC3339))
+	} else {
+		fmt.Fprintln(w, "database built:   unknown")
+	}
+	if schema := scan.DBSchemaVersion(); schema != "" {
+		fmt.Fprintf(w, "database schema:  %s\n", schema)
+	}
+	if path := scan.DBPath(); path != "" {
+		fmt.Fprintf(w, "database path:    %s\n", path)
+	}
+	fmt.Fprintln(w, "matchers:")
+	for _, m := range scan.Matchers() {
+		fmt.Fprintf(w, "  - %s\n", m)
+	}
+	return exitStatus{Reason: reasonDiagnostics}
+}
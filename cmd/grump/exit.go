@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// exitReason explains an exit code. The values are printed by -print-exit-reason and are
+// stable, so scripts can depend on them.
+type exitReason string
+
+const (
+	// reasonNoVulnerabilities (0): nothing to report. Count is 0.
+	reasonNoVulnerabilities exitReason = "no_vulnerabilities"
+	// reasonAllFixed (0): every fixable vulnerability was patched, or would be in a dry run.
+	// Count is the number of vulnerabilities fixed.
+	reasonAllFixed exitReason = "all_fixed"
+	// reasonNoNewFindings (0): in incremental mode, every new finding was fixed. Count is 0.
+	reasonNoNewFindings exitReason = "no_new_findings"
+	// reasonPartialFailures (1): some vulnerabilities could not be fixed. Count is the number
+	// of vulnerabilities left unfixed.
+	reasonPartialFailures exitReason = "partial_failures"
+	// reasonNewFindings (1): in incremental mode, new findings remain unfixed. Count is the
+	// number of unfixed new findings.
+	reasonNewFindings exitReason = "new_findings"
+	// reasonBuildFailed (1): the patched project doesn't build. Count is the number of modules.
+	reasonBuildFailed exitReason = "build_failed"
+	// reasonFailOn (3): vulnerabilities at or above the -fail-on severity remain. Count is the
+	// number of remaining vulnerabilities.
+	reasonFailOn exitReason = "fail_on_threshold"
+	// reasonTidyStrict (2): go mod tidy reported problems with -tidy-strict. Count is the
+	// number of problems.
+	reasonTidyStrict exitReason = "tidy_strict"
+	// reasonHookFailed (2): the -on-success or -on-failure command failed. Count is 1.
+	reasonHookFailed exitReason = "hook_failed"
+	// reasonWebhookFailed (2): the report couldn't be delivered to -webhook. Count is 1.
+	reasonWebhookFailed exitReason = "webhook_failed"
+	// reasonError (2): the run failed. Count is the number of modules that failed.
+	reasonError exitReason = "error"
+	// reasonUsage (2): the command line or config file is invalid. Count is 0.
+	reasonUsage exitReason = "usage"
+	// reasonDiagnostics (0): diagnostics such as -list-matchers were printed instead of
+	// scanning. Count is 0.
+	reasonDiagnostics exitReason = "diagnostics"
+)
+
+// exitStatus is the process exit code together with the reason for it
+type exitStatus struct {
+	Code   int
+	Reason exitReason
+	Count  int
+}
+
+var (
+	// statusError is the status of a module whose run failed
+	statusError = exitStatus{Code: 2, Reason: reasonError, Count: 1}
+	// statusUsage is the status of an invalid invocation
+	statusUsage = exitStatus{Code: 2, Reason: reasonUsage}
+)
+
+// exitCodeRanks orders exit codes from least to most severe: an error (2) outranks a
+// -fail-on threshold breach (3), which outranks unfixed vulnerabilities (1)
+var exitCodeRanks = map[int]int{0: 0, 1: 1, 3: 2, 2: 3}
+
+// worseExit returns the more severe of two exit statuses. Counts of statuses with the same
+// reason add up, so a multi-module run reports totals.
+func worseExit(a, b exitStatus) exitStatus {
+	if a.Code == b.Code && a.Reason == b.Reason {
+		a.Count += b.Count
+		return a
+	}
+	if exitCodeRanks[b.Code] > exitCodeRanks[a.Code] {
+		return b
+	}
+	return a
+}
+
+// printExitReason writes the status as a single line, e.g. "EXIT=1 reason=partial_failures count=2"
+func printExitReason(w io.Writer, status exitStatus) {
+	fmt.Fprintf(w, "EXIT=%d reason=%s count=%d\n", status.Code, status.Reason, status.Count)
+}
+
+// exit finishes the profiles, removes the clone of a remote repository, and terminates the
+// process with the status' exit code, printing the reason to stderr first with
+// -print-exit-reason
+func exit(status exitStatus, opts options) {
+	if opts.stopProfiles != nil {
+		opts.stopProfiles()
+	}
+	if opts.cloneDir != "" {
+		if err := os.RemoveAll(opts.cloneDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove clone at %s: %v\n", opts.cloneDir, err)
+		}
+	}
+	if opts.printExitReason {
+		printExitReason(os.Stderr, status)
+	}
+	os.Exit(status.Code)
+}
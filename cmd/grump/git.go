@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/divolgin/grump/pkg/reporter"
+	"github.com/divolgin/grump/pkg/vcs"
+)
+
+// startBranch checks that the work tree of the module is clean and checks out a new branch
+// for the fixes
+func startBranch(goModPath, branch string) error {
+	dir := filepath.Dir(goModPath)
+	if err := vcs.CheckClean(dir); err != nil {
+		return err
+	}
+	if err := vcs.CreateBranch(dir, branch); err != nil {
+		return err
+	}
+	slog.Info("Created branch for fixes", "branch", branch)
+	return nil
+}
+
+// commitFixes commits the go.mod and go.sum changes of the applied updates to the current
+// branch, with a message listing the vulnerabilities they fix
+func commitFixes(goModPath string, report *reporter.Report) error {
+	message := vcs.CommitMessage(report.Results())
+	if message == "" {
+		slog.Info("No updates applied, nothing to commit")
+		return nil
+	}
+
+	dir := filepath.Dir(goModPath)
+	paths := []string{filepath.Base(goModPath)}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); err == nil {
+		paths = append(paths, "go.sum")
+	}
+
+	err := vcs.CommitChanges(dir, message, paths...)
+	if errors.Is(err, vcs.ErrNothingToCommit) {
+		slog.Info("go.mod and go.sum are unchanged, nothing to commit")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit fixes: %w", err)
+	}
+	slog.Info("Committed fixes")
+	return nil
+}
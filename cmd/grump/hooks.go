@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/reporter"
+)
+
+// runHook runs the -on-success command when the run exits with 0, the -on-failure command
+// otherwise. The command runs with sh -c in the project directory, with the outcome in
+// GRUMP_* environment variables, see hookEnv. Its output is streamed to stderr so it can't
+// corrupt the report on stdout. A failing hook fails the run.
+func runHook(status exitStatus, report *reporter.Report, goModPath string, opts options) exitStatus {
+	command := opts.onFailure
+	if status.Code == 0 {
+		command = opts.onSuccess
+	}
+	if command == "" {
+		return status
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	if goModPath != "" {
+		cmd.Dir = filepath.Dir(goModPath)
+	}
+	cmd.Env = append(os.Environ(), hookEnv(status, report, goModPath, opts)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	slog.Info("Running hook", "command", command)
+	if err := cmd.Run(); err != nil {
+		slog.Error("Hook failed", "command", command, "error", err)
+		return worseExit(status, exitStatus{Code: 2, Reason: reasonHookFailed, Count: 1})
+	}
+	return status
+}
+
+// hookEnv returns the environment variables describing the run for a hook
+func hookEnv(status exitStatus, report *reporter.Report, goModPath string, opts options) []string {
+	var total, fixed, failed, unfixable int
+	var packages, failedPackages []string
+	if report != nil {
+		total, fixed, failed = report.TotalVulnerabilities, report.VulnerabilitiesFixed, report.VulnerabilitiesFailed
+		unfixable = len(report.Unfixable)
+		for _, update := range report.Updates {
+			switch {
+			case update.Success:
+				packages = append(packages, update.Package+"@"+update.TargetVersion)
+			case !update.Skipped:
+				failedPackages = append(failedPackages, update.Package)
+			}
+		}
+	}
+
+	projectDir := ""
+	if goModPath != "" {
+		projectDir = filepath.Dir(goModPath)
+	}
+
+	return []string{
+		fmt.Sprintf("GRUMP_EXIT_CODE=%d", status.Code),
+		"GRUMP_EXIT_REASON=" + string(status.Reason),
+		"GRUMP_PROJECT_DIR=" + projectDir,
+		"GRUMP_DRY_RUN=" + strconv.FormatBool(opts.DryRun),
+		fmt.Sprintf("GRUMP_TOTAL_COUNT=%d", total),
+		fmt.Sprintf("GRUMP_FIXED_COUNT=%d", fixed),
+		fmt.Sprintf("GRUMP_FAILED_COUNT=%d", failed),
+		fmt.Sprintf("GRUMP_UNFIXABLE_COUNT=%d", unfixable),
+		"GRUMP_PACKAGES=" + strings.Join(packages, " "),
+		"GRUMP_FAILED_PACKAGES=" + strings.Join(failedPackages, " "),
+	}
+}
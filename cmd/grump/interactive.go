@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompter asks on the terminal whether to apply each update, for -interactive
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// all approves the remaining updates without asking, quit declines them
+	all  bool
+	quit bool
+}
+
+// newPrompter returns a prompter that reads answers from in and writes questions to out
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// confirm asks whether to apply upd until it gets a valid answer: y(es), n(o), a(ll) to apply
+// this and every remaining update, or q(uit) to skip this and every remaining update. The end
+// of input counts as quit.
+func (p *prompter) confirm(upd scanner.PackageUpdate) bool {
+	if p.all || p.quit {
+		return p.all
+	}
+
+	vulns := upd.VulnIDs
+	if len(vulns) == 0 {
+		vulns = []string{upd.VulnID}
+	}
+	for {
+		fmt.Fprintf(p.out, "Update %s %s -> %s (%s: %s)? [y/n/a/q] ",
+			upd.Name, upd.CurrentVersion, upd.TargetVersion, upd.SeverityForGating(), strings.Join(vulns, ", "))
+		answer, err := p.in.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(p.out)
+			p.quit = true
+			return false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "a", "all":
+			p.all = true
+			return true
+		case "q", "quit":
+			p.quit = true
+			return false
+		}
+		fmt.Fprintln(p.out, "Please answer y (yes), n (no), a (all remaining), or q (quit, skipping the rest).")
+	}
+}
@@ -1,127 +1,821 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/divolgin/grump"
+	"github.com/divolgin/grump/pkg/config"
 	"github.com/divolgin/grump/pkg/patcher"
 	"github.com/divolgin/grump/pkg/reporter"
 	"github.com/divolgin/grump/pkg/scanner"
+	"github.com/divolgin/grump/pkg/state"
+	"github.com/divolgin/grump/pkg/vcs"
+	"github.com/divolgin/grump/pkg/webhook"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// formatAll writes every output format to -output-dir
+const formatAll = "all"
+
+// options holds the parsed command line options: the pipeline options plus those that only
+// affect how the CLI renders the report and exits
+type options struct {
+	grump.Options
+	outputFormat string
+	tidyStrict   bool
+	failOn       string
+	outputPath   string
+	metricsFile  string
+	recursive    bool
+	workspace    bool
+	gitBranch    string
+	// stdin reads the go.mod paths to process from standard input, see readModulePaths
+	stdin bool
+	// modulePaths are the go.mod paths read from stdin or given as several path arguments
+	modulePaths []string
+	concurrency int
+	// onSuccess and onFailure are shell commands run after a single-module run, see runHook
+	onSuccess string
+	onFailure string
+	// webhook delivers the JSON report after a single-module run, see sendWebhook
+	webhook         webhook.Config
+	webhookOptional bool
+	// outputDir receives a file per output format with -format all, see reporter.Report.WriteDir
+	outputDir string
+	// printExitReason prints the exit code and its reason to stderr before exiting, see exitStatus
+	printExitReason bool
+	// cloneDir is the temporary clone of a remote repository argument, removed on exit
+	cloneDir string
+	// stopProfiles finishes the -cpuprofile and -memprofile profiles on exit, see startProfiles
+	stopProfiles func()
+}
+
+// applyConfig fills in options from a config file. Flags set on the command line win.
+func (o *options) applyConfig(cfg *config.Config) {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if cfg.Format != "" && !set["format"] {
+		o.outputFormat = cfg.Format
+	}
+	if cfg.MinSeverity != "" && !set["min-severity"] {
+		o.MinSeverity = cfg.MinSeverity
+	}
+	if cfg.FixStrategy != "" && !set["fix-strategy"] {
+		o.FixStrategy = scanner.FixVersionStrategy(cfg.FixStrategy)
+	}
+	if cfg.TimeoutDuration() > 0 && !set["timeout"] {
+		o.Timeout = cfg.TimeoutDuration()
+	}
+	o.IgnoreRules = cfg.Ignore
+	// Overrides from the command line win for the same vulnerability
+	for id, severity := range cfg.SeverityOverrides {
+		if _, ok := o.SeverityOverrides[id]; !ok {
+			if o.SeverityOverrides == nil {
+				o.SeverityOverrides = make(map[string]string)
+			}
+			o.SeverityOverrides[id] = severity
+		}
+	}
+}
+
 func main() {
 	// Parse command line flags
-	outputFormat := flag.String("format", "text", "Output format (text or json)")
-	grypeConfig := flag.String("grype-config", "", "Path to grype config file for ignoring vulnerabilities and modules")
+	var opts options
+	flag.StringVar(&opts.outputFormat, "format", "text", "Output format ("+strings.Join(reporter.Formats, ", ")+"), or all to write every format to -output-dir")
+	flag.StringVar(&opts.outputDir, "output-dir", "", "Directory to write a report per format to with -format all, printing the text report to stdout")
+	flag.StringVar(&opts.outputPath, "output", "", "Also write the report to this file, whatever the -format: HTML for .html or .htm files, JSON otherwise")
+	flag.StringVar(&opts.metricsFile, "metrics-file", "", "Write vulnerability counts by severity to this file in Prometheus text format, for the node exporter textfile collector")
+	flag.StringVar(&opts.Template, "template", "", "Go text/template to render the report with; implies -format template")
+	templateFile := flag.String("template-file", "", "File with a Go text/template to render the report with; implies -format template")
+	configPath := flag.String("config", "", "Path to a grump config file (YAML or JSON); flags override its values")
+	flag.StringVar(&opts.GrypeConfigPath, "grype-config", "", "Path to grype config file for ignoring vulnerabilities and modules")
+	flag.BoolVar(&opts.Progress, "progress", false, "Print SBOM cataloging and vulnerability database download progress to stderr")
+	flag.BoolVar(&opts.SummaryOnly, "summary-only", false, "Print only the summary line of the text report, even when nothing was found")
+	flag.BoolVar(&opts.Explain, "explain", false, "Explain how each update's target version was chosen in text and JSON output")
+	flag.BoolVar(&opts.Verbose, "verbose", false, "Include diagnostic details such as go mod tidy output in the report")
+	flag.BoolVar(&opts.tidyStrict, "tidy-strict", false, "Fail the run when go mod tidy reports warnings or errors")
+	flag.StringVar(&opts.CacheDir, "cache-dir", "", "Cache SBOMs in this directory, keyed by go.sum, and reuse them while go.sum is unchanged")
+	flag.StringVar(&opts.SBOMPath, "sbom", "", "Path to a prebuilt syft SBOM to match instead of cataloging the project")
+	flag.StringVar(&opts.BinaryPath, "binary", "", "Path to a compiled Go binary to scan instead of a project (report only)")
+	flag.StringVar(&opts.View, "view", reporter.ViewPackage, "Group results by package or advisory (package or advisory)")
+	flag.StringVar(&opts.SortBy, "sort-by", reporter.SortSeverity, "Order updates by severity or by EPSS exploit probability (severity or epss)")
+	flag.BoolVar(&opts.IncludeOSPackages, "include-os-packages", false, "Also report vulnerabilities in OS and other non-Go packages found in the project directory (report only)")
+	flag.IntVar(&opts.MaxPasses, "max-passes", 1, "Re-scan after patching and bump modules whose fix version is vulnerable again, up to this many passes")
+	flag.BoolVar(&opts.RequireInGoMod, "require-in-gomod", false, "Only fix modules listed in the go.mod require block, dropping findings for modules outside the build such as test fixtures")
+	flag.BoolVar(&opts.GoOnly, "go-only", false, "Match Go modules only, skipping the matchers of other ecosystems (faster for pure Go projects)")
+	flag.BoolVar(&opts.NormalizeByCVE, "normalize-by-cve", false, "Key findings by CVE, collapsing duplicate GHSA/CVE advisories")
+	flag.BoolVar(&opts.AllowCreateGoSum, "allow-create-gosum", false, "Allow patching to create go.sum when the project has none")
+	flag.BoolVar(&opts.EscalateKEV, "escalate-kev", false, "Escalate effective severity of known exploited (CISA KEV) and high-EPSS vulnerabilities")
+	flag.BoolVar(&opts.EmbedGoMod, "embed-gomod", false, "Embed go.mod and go.sum contents before and after patching in JSON output")
+	flag.BoolVar(&opts.ShowDiff, "show-diff", false, "Append a unified diff of the go.mod and go.sum changes to the report")
+	flag.BoolVar(&opts.RespectMajor, "respect-major", false, "Don't apply fixes that are in a different major version than the current one")
+	flag.BoolVar(&opts.VerifyVersions, "verify-versions", false, "Check that fix versions are published on the module proxy, falling back to the next fix version if not")
+	flag.BoolVar(&opts.NoTidy, "no-tidy", false, "Don't run go mod tidy after patching")
+	flag.BoolVar(&opts.GetFallback, "get-fallback", false, "Retry updates gobump can't apply with go get <module>@<version>")
+	flag.BoolVar(&opts.VerifyBuild, "verify-build", false, "Run go build ./... after patching; restore go.mod and go.sum and fail the run if it no longer builds")
+	flag.BoolVar(&opts.RollbackBroken, "rollback-broken", false, "Verify the build after patching and roll back the updates that broke it")
+	flag.StringVar(&opts.MinSeverity, "min-severity", "", "Only fix vulnerabilities at or above this severity (negligible, low, medium, high, critical)")
+	flag.BoolVar(&opts.OnlyDirect, "only-direct", false, "Only patch direct dependencies; report indirect ones as needing an upstream fix")
+	flag.StringVar(&opts.MaxSeverity, "max-severity", "", "Leave vulnerabilities above this severity for manual review instead of fixing them")
+	flag.StringVar(&opts.failOn, "fail-on", "", "Exit with code 3 if a vulnerability at or above this severity remains after patching")
+	fixStrategy := flag.String("fix-strategy", string(scanner.FixLowest), "Fix version to target when an advisory lists several (lowest or highest)")
+	flag.StringVar(&opts.MinConfidence, "min-confidence", "", "Skip patching matches below this confidence (low, medium, high)")
+	flag.StringVar(&opts.BaselinePath, "baseline", "", "JSON report from an earlier run to compare findings against (added, removed, unchanged)")
+	flag.StringVar(&opts.StatePath, "state", "", "State file for incremental runs: report changes since the last run and gate only on new findings")
+	flag.StringVar(&opts.gitBranch, "git-branch", "", "Create this git branch before patching and commit the fixes to it; the work tree must be clean")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "Report the updates that would be made without modifying go.mod or go.sum")
+	flag.IntVar(&opts.concurrency, "concurrency", 1, "Number of modules to process in parallel with -recursive")
+	flag.BoolVar(&opts.workspace, "workspace", false, "Scan and patch every module of the go.work in the given directory (detected automatically when there is no go.mod)")
+	flag.BoolVar(&opts.stdin, "stdin", false, "Scan and patch the modules whose go.mod paths or directories are read from stdin, one per line")
+	flag.BoolVar(&opts.recursive, "recursive", false, "Scan and patch every module (go.mod) under the given path")
+	flag.IntVar(&opts.DBRetries, "db-retries", 3, "Retry loading the vulnerability database this many times, with exponential backoff, after a network error")
+	flag.DurationVar(&opts.DBMaxAge, "db-max-age", 0, "Reuse the installed vulnerability database without checking for updates if it was built within this long (e.g. 24h); 0 always checks")
+	flag.BoolVar(&opts.Offline, "offline", false, "Use the installed vulnerability database as is, without checking for updates or validating its age")
+	flag.StringVar(&opts.DBDir, "db-dir", "", "Directory of the vulnerability database (default: grype's cache directory)")
+	flag.DurationVar(&opts.Timeout, "timeout", 0, "Abort a module's scan, or stop patching between packages, if it takes longer than this (e.g. 5m); 0 disables the limit")
+	flag.BoolVar(&opts.CheckLatest, "check-latest", false, "Note when a newer release than the selected fix version is available")
+	fixStates := flag.String("fix-states", "", "Comma-separated fix states to report (fixed, not-fixed, wont-fix, unknown); default all")
+	goVersions := flag.String("go-versions", "", "Comma-separated Go versions (e.g. 1.21,1.22) to dry-run update resolution under")
+	var metaFlags stringSliceFlag
+	flag.Var(&metaFlags, "meta", "Metadata key=value to attach to the report (repeatable, overrides CI auto-detection)")
+	var ignoreFlags stringSliceFlag
+	flag.Var(&ignoreFlags, "ignore", "Ignore a vulnerability ID such as GHSA-xxxx or CVE-2024-1234; a trailing * matches a prefix (repeatable)")
+	since := flag.String("since", "", "Only fix vulnerabilities published since this date (2024-01-31) or within this duration (e.g. 30d, 72h)")
+	flag.BoolVar(&opts.StrictSince, "strict-since", false, "With -since, also drop vulnerabilities whose publish date is unknown")
+	flag.BoolVar(&opts.SinceOSV, "since-osv", false, "With -since, look up publish dates missing from the vulnerability database on OSV.dev")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile of the run to this file, for go tool pprof")
+	memProfile := flag.String("memprofile", "", "Write a heap profile at the end of the run to this file, for go tool pprof")
+	interactive := flag.Bool("interactive", false, "Ask on the terminal before applying each update (y/n/all/quit)")
+	listMatchers := flag.Bool("list-matchers", false, "Print the enabled matchers, vulnerability database, and grype and syft versions, then exit")
+	var alwaysFixFlags stringSliceFlag
+	flag.Var(&alwaysFixFlags, "always-fix", "Fix these modules regardless of -min-severity; exact paths or globs like golang.org/x/* (repeatable or comma-separated)")
+	flag.BoolVar(&opts.AutoSync, "auto-sync", false, "Run go mod download before scanning when go.sum is missing entries go.mod needs")
+	flag.StringVar(&opts.onSuccess, "on-success", "", "Shell command to run after a run that exits with 0; GRUMP_* variables describe the outcome")
+	flag.StringVar(&opts.onFailure, "on-failure", "", "Shell command to run after a run that exits with a non-zero code; GRUMP_* variables describe the outcome")
+	flag.StringVar(&opts.webhook.URL, "webhook", "", "POST the JSON report to this URL after the run")
+	var webhookHeaderFlags stringSliceFlag
+	flag.Var(&webhookHeaderFlags, "webhook-header", "Header to send with the webhook, as \"Name: value\" (repeatable)")
+	webhookSecretEnv := flag.String("webhook-secret-env", "", "Sign the webhook body with HMAC-SHA256 using the secret in this environment variable")
+	flag.BoolVar(&opts.webhookOptional, "webhook-optional", false, "Don't fail the run when the webhook can't be delivered")
+	var envFlags stringSliceFlag
+	flag.Var(&envFlags, "env", "Set KEY=VALUE in the environment of the go commands run while patching, e.g. GOPROXY or GOPRIVATE (repeatable)")
+	var severityOverrideFlags stringSliceFlag
+	flag.Var(&severityOverrideFlags, "severity-override", "Report a vulnerability with a different severity, e.g. CVE-2024-1234=low (repeatable or comma-separated)")
+	var excludeFlags stringSliceFlag
+	flag.Var(&excludeFlags, "exclude", "With -include-os-packages, leave paths matching this glob out of cataloging the project directory, e.g. '**/testdata/**' or 'vendor/**' (repeatable)")
+	var prefixFlags stringSliceFlag
+	flag.Var(&prefixFlags, "patch-prefix", "Only auto-patch modules under this path prefix (repeatable); others are reported as deferred")
+	logLevel := flag.String("log-level", "info", "Minimum level of diagnostic messages on stderr (debug, info, warn, error)")
+	logFormat := flag.String("log-format", logFormatText, "Format of diagnostic messages on stderr (text or json)")
+	flag.BoolVar(&opts.printExitReason, "print-exit-reason", false, "Print the exit code and a stable reason code to stderr before exiting, e.g. EXIT=1 reason=partial_failures count=2")
+	quiet := flag.Bool("quiet", false, "Print nothing to stderr except errors that fail the run (same as -log-level error)")
 	flag.Parse()
 
+	// Profile everything after flag parsing; exit stops the profiles on every path
+	stopProfiles, err := startProfiles(*cpuProfile, *memProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
+	}
+	opts.stopProfiles = stopProfiles
+
+	if *quiet {
+		if opts.Progress {
+			fmt.Fprintln(os.Stderr, "Error: -quiet and -progress are mutually exclusive.")
+			exit(statusUsage, opts)
+		}
+		*logLevel = "error"
+	}
+	if opts.MaxPasses < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -max-passes must be at least 1.")
+		exit(statusUsage, opts)
+	}
+	if opts.GoOnly && opts.IncludeOSPackages {
+		fmt.Fprintln(os.Stderr, "Error: -go-only and -include-os-packages are mutually exclusive.")
+		exit(statusUsage, opts)
+	}
+	if len(excludeFlags) > 0 && !opts.IncludeOSPackages {
+		// Go modules come from go.mod alone, so there is nothing else to exclude paths from
+		fmt.Fprintln(os.Stderr, "Error: -exclude requires -include-os-packages.")
+		exit(statusUsage, opts)
+	}
+
+	// Diagnostics go to stderr through slog; the report itself is written to stdout
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
+	}
+	slog.SetDefault(logger)
+
+	opts.FixStrategy = scanner.FixVersionStrategy(*fixStrategy)
+	opts.Version = version
+	for _, value := range severityOverrideFlags {
+		for _, pair := range strings.Split(value, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			id, severity, ok := strings.Cut(pair, "=")
+			if !ok || id == "" || severity == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid -severity-override %q: must be ID=severity, e.g. CVE-2024-1234=low\n", pair)
+				exit(statusUsage, opts)
+			}
+			if opts.SeverityOverrides == nil {
+				opts.SeverityOverrides = make(map[string]string)
+			}
+			opts.SeverityOverrides[strings.TrimSpace(id)] = strings.TrimSpace(severity)
+		}
+	}
+	if *configPath != "" {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		opts.applyConfig(cfg)
+	}
+	if *templateFile != "" {
+		if opts.Template != "" {
+			fmt.Fprintln(os.Stderr, "Error: -template and -template-file are mutually exclusive.")
+			exit(statusUsage, opts)
+		}
+		data, err := os.ReadFile(*templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read template file: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		opts.Template = string(data)
+	}
+	// A template selects the template format unless a format was chosen explicitly
+	formatSet := false
+	flag.Visit(func(f *flag.Flag) {
+		formatSet = formatSet || f.Name == "format"
+	})
+	if opts.Template != "" && !formatSet {
+		opts.outputFormat = "template"
+	}
+	if opts.SummaryOnly && opts.outputFormat != "text" {
+		fmt.Fprintln(os.Stderr, "Error: -summary-only requires the text format.")
+		exit(statusUsage, opts)
+	}
+	opts.PatchPrefixes = prefixFlags
+	opts.Env = envFlags
+	if err := patcher.ValidateEnv(opts.Env); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -env: %v\n", err)
+		exit(statusUsage, opts)
+	}
+	opts.IgnoreVulns = ignoreFlags
+	opts.ExcludePaths = excludeFlags
+	if *since != "" {
+		cutoff, err := parseSince(*since, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		opts.Since = cutoff
+	} else if opts.StrictSince || opts.SinceOSV {
+		fmt.Fprintln(os.Stderr, "Error: -strict-since and -since-osv require -since.")
+		exit(statusUsage, opts)
+	}
+	for _, value := range alwaysFixFlags {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				opts.AlwaysFix = append(opts.AlwaysFix, pattern)
+			}
+		}
+	}
+	if err := opts.configureWebhook(webhookHeaderFlags, *webhookSecretEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
+	}
+	for _, state := range strings.Split(*fixStates, ",") {
+		if state = strings.TrimSpace(state); state != "" {
+			opts.FixStates = append(opts.FixStates, vulnerability.FixState(state))
+		}
+	}
+	for _, v := range strings.Split(*goVersions, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			opts.GoVersions = append(opts.GoVersions, v)
+		}
+	}
+
 	// Get the project path from arguments
 	args := flag.Args()
-	if len(args) < 1 {
+	if len(args) < 1 && opts.SBOMPath == "" && opts.BinaryPath == "" && !opts.stdin {
 		fmt.Fprintf(os.Stderr, "Usage: grump [options] <path>\n")
+		fmt.Fprintf(os.Stderr, "       grump [options] <path> <path>...\n")
+		fmt.Fprintf(os.Stderr, "       grump [options] <git-url>\n")
+		fmt.Fprintf(os.Stderr, "       grump -sbom <file> [options] [path]\n")
+		fmt.Fprintf(os.Stderr, "       grump -binary <file> [options]\n")
+		fmt.Fprintf(os.Stderr, "       grump -stdin [options] < paths.txt\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nNote: Options must come before the path argument.\n")
 		fmt.Fprintf(os.Stderr, "Example: grump -format json /path/to/project\n")
-		os.Exit(2)
+		exit(statusUsage, opts)
+	}
+
+	// A remote repository is cloned and scanned in report-only mode
+	remote := len(args) == 1 && vcs.IsRemoteURL(args[0])
+	if remote && (opts.stdin || opts.BinaryPath != "" || opts.SBOMPath != "" || opts.gitBranch != "") {
+		fmt.Fprintln(os.Stderr, "Error: a remote repository cannot be combined with -stdin, -binary, -sbom, or -git-branch.")
+		exit(statusUsage, opts)
 	}
 
-	// Validate that there's exactly one positional argument
+	// Several paths are processed together like -stdin; each must be a module
 	if len(args) > 1 {
-		fmt.Fprintf(os.Stderr, "Error: too many arguments. Expected 1 path, got %d arguments: %v\n", len(args), args)
-		fmt.Fprintf(os.Stderr, "\nUsage: grump [options] <path>\n")
-		fmt.Fprintf(os.Stderr, "\nNote: Options must come before the path argument.\n")
-		fmt.Fprintf(os.Stderr, "Example: grump -format json /path/to/project\n")
-		os.Exit(2)
+		if opts.recursive || opts.workspace || opts.stdin || opts.BinaryPath != "" || opts.SBOMPath != "" {
+			fmt.Fprintln(os.Stderr, "Error: multiple paths cannot be combined with -recursive, -workspace, -stdin, -binary, or -sbom.")
+			fmt.Fprintf(os.Stderr, "\nNote: Options must come before the path arguments.\n")
+			exit(statusUsage, opts)
+		}
+		for _, arg := range args {
+			if vcs.IsRemoteURL(arg) {
+				fmt.Fprintf(os.Stderr, "Error: remote repository %s must be the only path.\n", vcs.RedactURL(arg))
+				exit(statusUsage, opts)
+			}
+			goModPath, err := resolveGoModPath(arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(statusUsage, opts)
+			}
+			opts.modulePaths = append(opts.modulePaths, goModPath)
+		}
 	}
 
-	projectPath := args[0]
+	// Paths from stdin replace the path argument
+	if opts.stdin && (len(args) > 0 || opts.recursive || opts.workspace || opts.BinaryPath != "") {
+		fmt.Fprintln(os.Stderr, "Error: -stdin does not take a path and cannot be combined with -recursive, -workspace, or -binary.")
+		exit(statusUsage, opts)
+	}
 
-	// Validate output format
-	if *outputFormat != "text" && *outputFormat != "json" {
-		fmt.Fprintf(os.Stderr, "Error: invalid output format '%s'. Must be 'text' or 'json'.\n", *outputFormat)
-		os.Exit(2)
+	// Answers are read from the terminal, so there must be one
+	if *interactive {
+		if opts.stdin {
+			fmt.Fprintln(os.Stderr, "Error: -interactive cannot be combined with -stdin.")
+			exit(statusUsage, opts)
+		}
+		if !isTerminal(os.Stdin) {
+			fmt.Fprintln(os.Stderr, "Error: -interactive needs a terminal on stdin; drop -interactive when running in CI or with piped input.")
+			exit(statusUsage, opts)
+		}
+		opts.Confirm = newPrompter(os.Stdin, os.Stderr).confirm
+	}
+
+	// A binary replaces the project entirely
+	if opts.BinaryPath != "" {
+		if opts.SBOMPath != "" || opts.recursive || opts.gitBranch != "" {
+			fmt.Fprintln(os.Stderr, "Error: -binary cannot be combined with -sbom, -recursive, or -git-branch.")
+			exit(statusUsage, opts)
+		}
+		if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: -binary does not take a project path; binaries are scanned in report-only mode.")
+			exit(statusUsage, opts)
+		}
+		if _, err := os.Stat(opts.BinaryPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: binary not found at %s\n", opts.BinaryPath)
+			exit(statusUsage, opts)
+		}
+	}
+
+	// Validate report view
+	if opts.View != reporter.ViewPackage && opts.View != reporter.ViewAdvisory {
+		fmt.Fprintf(os.Stderr, "Error: invalid view '%s'. Must be 'package' or 'advisory'.\n", opts.View)
+		exit(statusUsage, opts)
+	}
+	if opts.SortBy != reporter.SortSeverity && opts.SortBy != reporter.SortEPSS {
+		fmt.Fprintf(os.Stderr, "Error: invalid sort order '%s'. Must be 'severity' or 'epss'.\n", opts.SortBy)
+		exit(statusUsage, opts)
+	}
+
+	// Validate severity threshold
+	if opts.MinSeverity != "" && scanner.SeverityRank(opts.MinSeverity) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid minimum severity '%s'. Must be negligible, low, medium, high, or critical.\n", opts.MinSeverity)
+		exit(statusUsage, opts)
+	}
+
+	if opts.Offline && opts.DBMaxAge > 0 {
+		fmt.Fprintln(os.Stderr, "Error: -offline cannot be combined with -db-max-age; offline runs never update the database.")
+		exit(statusUsage, opts)
+	}
+
+	// Validate severity cap
+	if opts.MaxSeverity != "" && scanner.SeverityRank(opts.MaxSeverity) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid maximum severity '%s'. Must be negligible, low, medium, high, or critical.\n", opts.MaxSeverity)
+		exit(statusUsage, opts)
+	}
+	if opts.MinSeverity != "" && opts.MaxSeverity != "" && scanner.SeverityRank(opts.MinSeverity) > scanner.SeverityRank(opts.MaxSeverity) {
+		fmt.Fprintf(os.Stderr, "Error: -min-severity %s is above -max-severity %s; nothing would be fixed.\n", opts.MinSeverity, opts.MaxSeverity)
+		exit(statusUsage, opts)
+	}
+
+	// Validate failure threshold
+	if opts.failOn != "" && scanner.SeverityRank(opts.failOn) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -fail-on severity '%s'. Must be negligible, low, medium, high, or critical.\n", opts.failOn)
+		exit(statusUsage, opts)
+	}
+
+	// Validate fix version strategy
+	if err := opts.FixStrategy.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
 	}
 
-	// Make path absolute
-	absPath, err := filepath.Abs(projectPath)
+	// Validate Go versions for resolution checks
+	for _, v := range opts.GoVersions {
+		if _, err := patcher.ToolchainName(v); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusUsage, opts)
+		}
+	}
+
+	// Validate the patch policy before doing any work
+	policy := patcher.Policy{AllowedPrefixes: opts.PatchPrefixes, MinConfidence: opts.MinConfidence, MaxSeverity: opts.MaxSeverity}
+	if err := policy.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
+	}
+
+	// Auto-detected CI metadata is overridden by explicit -meta flags
+	explicitMeta, err := reporter.ParseMetadata(metaFlags)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
-		os.Exit(2)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(statusUsage, opts)
+	}
+	opts.Metadata = reporter.DetectCIMetadata()
+	for key, value := range explicitMeta {
+		opts.Metadata[key] = value
+	}
+
+	// Every format is written to its own file, and the text report to stdout
+	if (opts.outputFormat == formatAll) != (opts.outputDir != "") {
+		fmt.Fprintln(os.Stderr, "Error: -format all and -output-dir must be used together.")
+		exit(statusUsage, opts)
+	}
+	if opts.outputFormat == formatAll {
+		if opts.Template != "" {
+			fmt.Fprintln(os.Stderr, "Error: -format all does not include the template format; drop -template or use -format template.")
+			exit(statusUsage, opts)
+		}
+		opts.outputFormat = "text"
+	}
+
+	// Validate output format
+	if !reporter.IsValidFormat(opts.outputFormat) {
+		fmt.Fprintf(os.Stderr, "Error: invalid output format '%s'. Must be one of: %s.\n", opts.outputFormat, strings.Join(reporter.Formats, ", "))
+		exit(statusUsage, opts)
+	}
+
+	// Validate the custom template before anything is patched
+	if opts.outputFormat == "template" {
+		if opts.Template == "" {
+			fmt.Fprintln(os.Stderr, "Error: -format template requires -template or -template-file.")
+			exit(statusUsage, opts)
+		}
+		if _, err := reporter.ParseTemplate(opts.Template); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusUsage, opts)
+		}
+	}
+
+	// Diagnostics describe the scanner setup without scanning anything
+	if *listMatchers {
+		exit(printMatchers(os.Stdout, opts), opts)
 	}
 
-	// Determine the path to go.mod file
+	// Clone only once the options are known to be valid; exit removes the clone
+	if remote {
+		dir, err := cloneRemote(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusError, opts)
+		}
+		opts.cloneDir = dir
+		opts.RemoteURL = vcs.RedactURL(args[0])
+		args[0] = dir
+	}
+
+	// A go.work ties several modules together; its members are processed like -recursive
+	var goWorkPath string
+	if opts.workspace && (opts.recursive || len(args) == 0) {
+		fmt.Fprintln(os.Stderr, "Error: -workspace requires a path and cannot be combined with -recursive.")
+		exit(statusUsage, opts)
+	}
+	if !opts.recursive && len(args) == 1 {
+		absPath, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		goWorkPath, err = findWorkspace(absPath, opts.workspace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		opts.workspace = goWorkPath != ""
+	}
+
+	// Recursive, workspace, stdin, and multiple path runs process several modules and report
+	// them together
+	if opts.recursive || opts.workspace || opts.stdin || len(opts.modulePaths) > 0 {
+		mode := "-recursive"
+		if opts.workspace {
+			mode = "Workspace mode"
+		} else if opts.stdin {
+			mode = "-stdin"
+		} else if len(opts.modulePaths) > 0 {
+			mode = "Multiple paths"
+		}
+		if opts.SBOMPath != "" || opts.StatePath != "" || opts.outputPath != "" || opts.outputDir != "" || opts.metricsFile != "" || opts.BaselinePath != "" ||
+			opts.gitBranch != "" || opts.onSuccess != "" || opts.onFailure != "" || opts.webhook.URL != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s cannot be combined with -sbom, -state, -output, -output-dir, -metrics-file, -baseline, -git-branch, -on-success, -on-failure, or -webhook.\n", mode)
+			exit(statusUsage, opts)
+		}
+		if opts.Confirm != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s cannot be combined with -interactive; run grump on one module at a time.\n", mode)
+			exit(statusUsage, opts)
+		}
+		if !isRecursiveFormat(opts.outputFormat) {
+			fmt.Fprintf(os.Stderr, "Error: %s supports only the %s output formats.\n", mode, strings.Join(recursiveFormats, ", "))
+			exit(statusUsage, opts)
+		}
+		if opts.concurrency < 1 {
+			fmt.Fprintln(os.Stderr, "Error: -concurrency must be at least 1.")
+			exit(statusUsage, opts)
+		}
+		if opts.workspace {
+			exit(run(goWorkPath, opts), opts)
+		}
+		if opts.stdin {
+			goMods, err := readModulePaths(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(statusUsage, opts)
+			}
+			opts.modulePaths = goMods
+			exit(run("", opts), opts)
+		}
+		if len(opts.modulePaths) > 0 {
+			exit(run("", opts), opts)
+		}
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: -recursive requires a path.")
+			exit(statusUsage, opts)
+		}
+		root, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+			exit(statusUsage, opts)
+		}
+		exit(run(root, opts), opts)
+	}
+
+	// With -sbom the project path is optional; without one the findings are reported but not patched
 	var goModPath string
-	if filepath.Base(absPath) == "go.mod" {
-		// Input path already points to go.mod
-		goModPath = absPath
-	} else {
-		// Input path is a directory, append go.mod
-		goModPath = filepath.Join(absPath, "go.mod")
+	if len(args) == 1 {
+		// Make path absolute
+		absPath, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+			exit(statusUsage, opts)
+		}
+
+		// Determine the path to go.mod file
+		if filepath.Base(absPath) == "go.mod" {
+			// Input path already points to go.mod
+			goModPath = absPath
+		} else {
+			// Input path is a directory, append go.mod
+			goModPath = filepath.Join(absPath, "go.mod")
+		}
+
+		// Validate that go.mod exists
+		if _, err := os.Stat(goModPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: go.mod not found at %s\n", goModPath)
+			exit(statusUsage, opts)
+		}
 	}
 
-	// Validate that go.mod exists
-	if _, err := os.Stat(goModPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: go.mod not found at %s\n", goModPath)
-		os.Exit(2)
+	// Fixes can only be committed when something is patched
+	if opts.gitBranch != "" && (goModPath == "" || opts.DryRun) {
+		fmt.Fprintln(os.Stderr, "Error: -git-branch requires a project path and cannot be combined with -dry-run.")
+		exit(statusUsage, opts)
 	}
 
 	// Run the scan and fix process
-	exitCode := run(goModPath, *outputFormat, *grypeConfig)
-	os.Exit(exitCode)
+	exit(run(goModPath, opts), opts)
 }
 
-func run(goModPath string, outputFormat string, grypeConfigPath string) int {
-	// Initialize scanner
-	fmt.Fprintln(os.Stderr, "Initializing vulnerability scanner...")
-	scan, err := scanner.New(grypeConfigPath)
+// run scans and patches the module whose go.mod is at path, with -recursive every module
+// under the directory at path, in workspace mode every member of the go.work at path, or
+// with -stdin the modules read from stdin
+func run(path string, opts options) exitStatus {
+	runner, err := grump.NewRunner(opts.Options)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to initialize scanner: %v\n", err)
-		return 2
+		slog.Error("Failed to start", "error", err)
+		return runHook(statusError, nil, path, opts)
 	}
-	defer scan.Close()
+	defer runner.Close()
 
-	// Scan the project
-	fmt.Fprintf(os.Stderr, "Scanning project at %s for vulnerabilities...\n", goModPath)
-	matches, _, err := scan.Scan(goModPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to scan project: %v\n", err)
-		return 2
+	ctx := context.Background()
+	if opts.recursive {
+		return runRecursive(ctx, runner, path, opts)
+	}
+	if opts.workspace {
+		return runWorkspace(ctx, runner, path, opts)
+	}
+	if len(opts.modulePaths) > 0 {
+		return runModuleList(ctx, runner, opts)
 	}
 
-	// Get fixable updates
-	updates := scan.GetFixableUpdates(matches)
+	if opts.gitBranch != "" {
+		if err := startBranch(path, opts.gitBranch); err != nil {
+			slog.Error("Failed to create branch", "error", err)
+			return runHook(statusError, nil, path, opts)
+		}
+	}
 
-	if len(updates) == 0 {
-		fmt.Fprintln(os.Stderr, "No fixable vulnerabilities found.")
-		return 0
+	status, report := runModule(ctx, runner, path, opts, os.Stdout)
+	if opts.gitBranch != "" && report != nil {
+		if err := commitFixes(path, report); err != nil {
+			slog.Error("Failed to commit fixes", "error", err)
+			status = statusError
+		}
 	}
+	status = sendWebhook(ctx, status, report, opts)
+	return runHook(status, report, path, opts)
+}
 
-	// Initialize patcher with the project directory
-	projectDir := filepath.Dir(goModPath)
-	patch, err := patcher.New(projectDir)
+// runModule scans and patches a single module and writes its report to w.
+// It returns the exit status for the module and the report, nil if the run failed.
+func runModule(ctx context.Context, runner *grump.Runner, goModPath string, opts options, w io.Writer) (exitStatus, *reporter.Report) {
+	report, err := runner.RunModule(ctx, goModPath)
+	if errors.Is(err, grump.ErrWouldCreateGoSum) {
+		slog.Error("Run 'go mod tidy' first, or re-run with -allow-create-gosum to let grump create it", "error", err)
+		return statusError, nil
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to initialize patcher: %v\n", err)
-		return 2
+		slog.Error("Run failed", "error", err)
+		return statusError, nil
+	}
+
+	// The JSON artifact is written even when there is nothing to show on stdout
+	if opts.outputPath != "" {
+		if err := report.WriteFile(opts.outputPath, outputFileFormat(opts.outputPath)); err != nil {
+			slog.Error("Failed to save report", "error", err)
+			return statusError, report
+		}
+	}
+	if opts.outputDir != "" {
+		paths, err := report.WriteDir(opts.outputDir)
+		if err != nil {
+			slog.Error("Failed to save reports", "error", err)
+			return statusError, report
+		}
+		slog.Info("Saved reports", "dir", opts.outputDir, "files", len(paths))
+	}
+	if opts.metricsFile != "" {
+		if err := report.WriteMetricsFile(opts.metricsFile); err != nil {
+			slog.Error("Failed to save metrics", "error", err)
+			return statusError, report
+		}
+	}
+
+	if !report.HasFindings() && !opts.SummaryOnly {
+		slog.Info("No vulnerabilities found")
+		return exitStatus{Reason: reasonNoVulnerabilities}, report
+	}
+
+	if err := report.Write(w, opts.outputFormat); err != nil {
+		slog.Error("Failed to generate report", "error", err)
+		return statusError, report
+	}
+
+	return reportExitStatus(report, opts), report
+}
+
+// outputFileFormat returns the format of the -output file, chosen by its extension
+func outputFileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	default:
+		return "json"
+	}
+}
+
+// reportExitStatus translates a report into the process exit status: 2 for tidy problems in
+// strict mode, 3 when a vulnerability at or above the -fail-on severity remains, 1 when
+// vulnerabilities remain unfixed or the patched project doesn't build, 0 otherwise
+func reportExitStatus(report *reporter.Report, opts options) exitStatus {
+	// In strict mode any tidy warning or error fails the run
+	if opts.tidyStrict {
+		var problems []reporter.TidyReport
+		for _, msg := range report.TidyMessages {
+			if msg.Level != string(patcher.TidyLevelInfo) {
+				problems = append(problems, msg)
+			}
+		}
+		if len(problems) > 0 {
+			slog.Error("go mod tidy reported problems (-tidy-strict)", "count", len(problems))
+			for _, msg := range problems {
+				slog.Error("go mod tidy", "level", msg.Level, "message", msg.Message)
+			}
+			return exitStatus{Code: 2, Reason: reasonTidyStrict, Count: len(problems)}
+		}
 	}
 
-	// Apply updates
-	results := patch.UpdateAll(updates)
+	// A patched project that doesn't build is never a successful run
+	if report.BuildError != "" {
+		return exitStatus{Code: 1, Reason: reasonBuildFailed, Count: 1}
+	}
 
-	// Report results
-	rep := reporter.New(os.Stdout)
-	if err := rep.ReportResults(updates, results, outputFormat); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to generate report: %v\n", err)
-		return 2
+	// Any remaining vulnerability at or above the threshold fails the run, even in incremental mode
+	if opts.failOn != "" {
+		if remaining := remainingAtSeverity(report, opts.failOn); len(remaining) > 0 {
+			slog.Error("Vulnerabilities at or above the -fail-on severity remain", "count", len(remaining), "severity", opts.failOn)
+			for _, f := range remaining {
+				slog.Error("Remaining vulnerability", "module", f.Package, "version", f.Version, "vulnerability", f.VulnID, "severity", f.Severity)
+			}
+			return exitStatus{Code: 3, Reason: reasonFailOn, Count: len(remaining)}
+		}
+	}
+
+	// In incremental mode only findings introduced since the last run can fail it
+	if report.Changes != nil {
+		if unresolved := unresolvedIntroduced(report); len(unresolved) > 0 {
+			return exitStatus{Code: 1, Reason: reasonNewFindings, Count: len(unresolved)}
+		}
+		return exitStatus{Reason: reasonNoNewFindings}
 	}
 
 	// Determine exit code based on whether vulnerabilities remain unfixed
-	stats := reporter.AnalyzeResults(updates, results)
-	if stats.VulnerabilitiesFailed > 0 {
-		return 1 // Some vulnerabilities could not be fixed
+	if report.VulnerabilitiesFailed > 0 {
+		return exitStatus{Code: 1, Reason: reasonPartialFailures, Count: report.VulnerabilitiesFailed}
+	}
+
+	return exitStatus{Reason: reasonAllFixed, Count: report.VulnerabilitiesFixed}
+}
+
+// remainingAtSeverity returns the findings at or above minSeverity that this run didn't fix:
+// failed or skipped updates and vulnerabilities with no fix available
+func remainingAtSeverity(report *reporter.Report, minSeverity string) []state.Finding {
+	minRank := scanner.SeverityRank(minSeverity)
+	var remaining []state.Finding
+	for _, update := range report.Updates {
+		if update.Success || scanner.SeverityRank(update.EffectiveSeverity) < minRank {
+			continue
+		}
+		remaining = append(remaining, state.Finding{
+			Package:  update.Package,
+			Version:  update.CurrentVersion,
+			VulnID:   update.VulnID,
+			Severity: update.EffectiveSeverity,
+		})
 	}
+	for _, vuln := range report.Unfixable {
+		if scanner.SeverityRank(vuln.Severity) < minRank {
+			continue
+		}
+		remaining = append(remaining, state.Finding{
+			Package:  vuln.Package,
+			Version:  vuln.Version,
+			VulnID:   vuln.VulnID,
+			Severity: vuln.Severity,
+		})
+	}
+	return remaining
+}
 
-	return 0 // All vulnerabilities fixed
+// unresolvedIntroduced returns the new or reappeared findings that were not fixed by this run
+func unresolvedIntroduced(report *reporter.Report) []state.Finding {
+	updated := make(map[string]bool)
+	for _, update := range report.Updates {
+		if update.Success {
+			updated[update.Package] = true
+		}
+	}
+
+	var unresolved []state.Finding
+	for _, f := range report.Changes.Introduced() {
+		if !updated[f.Package] {
+			unresolved = append(unresolved, f)
+		}
+	}
+	return unresolved
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startProfiles starts writing a CPU profile to cpuPath and creates memPath for a heap
+// profile, skipping either when its path is empty. The returned function stops the CPU
+// profile and writes the heap profile; exit calls it so that profiles are complete on every
+// exit path.
+func startProfiles(cpuPath, memPath string) (func(), error) {
+	var cpuFile, memFile *os.File
+	if memPath != "" {
+		f, err := os.Create(memPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create memory profile: %w", err)
+		}
+		memFile = f
+	}
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			if memFile != nil {
+				memFile.Close()
+			}
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			if memFile != nil {
+				memFile.Close()
+			}
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuFile = f
+	}
+
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write CPU profile: %v\n", err)
+			}
+		}
+		if memFile != nil {
+			// Collect garbage first so the profile shows live memory
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(memFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write memory profile: %v\n", err)
+			}
+			if err := memFile.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write memory profile: %v\n", err)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/divolgin/grump"
+	"github.com/divolgin/grump/pkg/reporter"
+)
+
+// recursiveFormats are the output formats that can combine several module reports
+var recursiveFormats = []string{"text", "json", "tuples"}
+
+// isRecursiveFormat reports whether format supports -recursive
+func isRecursiveFormat(format string) bool {
+	for _, f := range recursiveFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// findModules returns the go.mod files under root. Like the go command's ./... pattern,
+// it skips vendor and testdata directories and directories starting with "." or "_".
+func findModules(root string) ([]string, error) {
+	var goMods []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			goMods = append(goMods, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find modules under %s: %w", root, err)
+	}
+	return goMods, nil
+}
+
+// runModuleBuffered runs a single module of a recursive run, capturing its report
+func runModuleBuffered(ctx context.Context, runner *grump.Runner, root, goModPath string, opts options) (reporter.ModuleOutput, exitStatus) {
+	rel, err := filepath.Rel(root, filepath.Dir(goModPath))
+	if err != nil {
+		rel = filepath.Dir(goModPath)
+	}
+
+	slog.Info("Processing module", "module", rel)
+	var output bytes.Buffer
+	status, report := runModule(ctx, runner, goModPath, opts, &output)
+
+	module := reporter.ModuleOutput{
+		Path:   rel,
+		Output: output.Bytes(),
+		Failed: status.Code == 2,
+	}
+	if report != nil {
+		module.Stats = report.Stats()
+	}
+	return module, status
+}
+
+// runRecursive scans and patches every module under root, opts.concurrency at a time, and
+// reports them together. A module that fails doesn't stop the others; the exit code is the
+// worst of all modules.
+func runRecursive(ctx context.Context, runner *grump.Runner, root string, opts options) exitStatus {
+	goMods, err := findModules(root)
+	if err != nil {
+		slog.Error("Failed to find modules", "error", err)
+		return statusError
+	}
+	if len(goMods) == 0 {
+		slog.Error("No go.mod files found", "root", root)
+		return statusError
+	}
+
+	modules, status := runModules(ctx, runner, root, goMods, opts)
+	return writeModules(modules, status, opts)
+}
+
+// runModules scans and patches the given modules, opts.concurrency at a time, and returns
+// their buffered reports in order along with the worst exit status
+func runModules(ctx context.Context, runner *grump.Runner, root string, goMods []string, opts options) ([]reporter.ModuleOutput, exitStatus) {
+	// Modules are processed by a bounded pool of workers, each with its own scanner clone.
+	// Reports are buffered and collected by index so the output order doesn't depend on timing.
+	modules := make([]reporter.ModuleOutput, len(goMods))
+	statuses := make([]exitStatus, len(goMods))
+	runPool(len(goMods), opts.concurrency, func(jobs <-chan int) {
+		moduleRunner := runner.Clone()
+		for i := range jobs {
+			modules[i], statuses[i] = runModuleBuffered(ctx, moduleRunner, root, goMods[i], opts)
+		}
+	})
+
+	status := exitStatus{Reason: reasonNoVulnerabilities}
+	for _, s := range statuses {
+		status = worseExit(status, s)
+	}
+	return modules, status
+}
+
+// runPool starts the given number of workers, hands out the indexes 0 to n-1 to them over the
+// jobs channel, and waits until all workers return
+func runPool(n, workers int, worker func(jobs <-chan int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(jobs)
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// writeModules writes the combined report of several modules to stdout and returns the exit status
+func writeModules(modules []reporter.ModuleOutput, status exitStatus, opts options) exitStatus {
+	if err := reporter.ReportModules(os.Stdout, opts.outputFormat, modules); err != nil {
+		slog.Error("Failed to generate report", "error", err)
+		return statusError
+	}
+	return status
+}
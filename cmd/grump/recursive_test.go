@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunPool(t *testing.T) {
+	for _, workers := range []int{1, 3, 20} {
+		var mu sync.Mutex
+		seen := make(map[int]int)
+		var running, peak atomic.Int32
+
+		runPool(10, workers, func(jobs <-chan int) {
+			for i := range jobs {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+
+				mu.Lock()
+				seen[i]++
+				mu.Unlock()
+			}
+		})
+
+		for i := 0; i < 10; i++ {
+			if seen[i] != 1 {
+				t.Errorf("workers %d: job %d ran %d times, want once", workers, i, seen[i])
+			}
+		}
+		if int(peak.Load()) > workers {
+			t.Errorf("workers %d: %d jobs ran at once", workers, peak.Load())
+		}
+	}
+}
+
+func TestFindModules(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"", "svc/api", "tools", "vendor/example.com/dep", "testdata/fixture", ".git/x", "_old"} {
+		path := filepath.Join(root, dir, "go.mod")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example.com/x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goMods, err := findModules(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, goMod := range goMods {
+		r, _ := filepath.Rel(root, goMod)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	if got, want := strings.Join(rel, ","), "go.mod,svc/api/go.mod,tools/go.mod"; got != want {
+		t.Errorf("findModules = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/divolgin/grump/pkg/vcs"
+)
+
+// cloneRemote makes a shallow clone of the repository at rawURL in a temporary directory and
+// returns the directory. The caller removes it, see exit.
+func cloneRemote(rawURL string) (string, error) {
+	dir, err := os.MkdirTemp("", "grump-clone-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	slog.Info("Cloning remote repository", "url", vcs.RedactURL(rawURL))
+	if err := vcs.Clone(rawURL, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/divolgin/grump"
+	"github.com/divolgin/grump/pkg/reporter"
+)
+
+// readModulePaths reads the modules to process, one go.mod path or module directory per line.
+// Blank lines and lines starting with "#" are skipped. Paths are made absolute, and each must
+// have a go.mod.
+func readModulePaths(r io.Reader) ([]string, error) {
+	var goMods []string
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		goModPath, err := resolveGoModPath(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		goMods = append(goMods, goModPath)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths from stdin: %w", err)
+	}
+	if len(goMods) == 0 {
+		return nil, fmt.Errorf("no go.mod paths read from stdin")
+	}
+	return goMods, nil
+}
+
+// resolveGoModPath returns the absolute path of the go.mod at path, a go.mod file or a module
+// directory, and checks that it exists
+func resolveGoModPath(path string) (string, error) {
+	goModPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	if filepath.Base(goModPath) != "go.mod" {
+		goModPath = filepath.Join(goModPath, "go.mod")
+	}
+	if _, err := os.Stat(goModPath); err != nil {
+		return "", fmt.Errorf("go.mod not found at %s", goModPath)
+	}
+	return goModPath, nil
+}
+
+// runModuleList scans and patches the modules read from stdin or given as several paths like
+// -recursive does, naming each module by its directory relative to the working directory.
+// In JSON the reports are written as an array rather than with a combined summary.
+func runModuleList(ctx context.Context, runner *grump.Runner, opts options) exitStatus {
+	root, err := os.Getwd()
+	if err != nil {
+		root = string(filepath.Separator)
+	}
+
+	modules, status := runModules(ctx, runner, root, opts.modulePaths, opts)
+	if err := reporter.ReportModuleList(os.Stdout, opts.outputFormat, modules); err != nil {
+		slog.Error("Failed to generate report", "error", err)
+		return statusError
+	}
+	return status
+}
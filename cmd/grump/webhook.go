@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/divolgin/grump/pkg/reporter"
+	"github.com/divolgin/grump/pkg/webhook"
+)
+
+// webhookRetries is how many times a failed webhook delivery is retried
+const webhookRetries = 3
+
+// configureWebhook validates the -webhook URL and sets the headers and the signing secret,
+// read from the secretEnv environment variable
+func (o *options) configureWebhook(headers []string, secretEnv string) error {
+	if o.webhook.URL == "" {
+		if len(headers) > 0 || secretEnv != "" || o.webhookOptional {
+			return fmt.Errorf("-webhook-header, -webhook-secret-env, and -webhook-optional require -webhook")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(o.webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid -webhook URL %q: must be an http or https URL", o.webhook.URL)
+	}
+
+	o.webhook.Headers = make(http.Header)
+	for _, header := range headers {
+		name, value, err := webhook.ParseHeader(header)
+		if err != nil {
+			return fmt.Errorf("-webhook-header: %w", err)
+		}
+		o.webhook.Headers.Add(name, value)
+	}
+
+	if secretEnv != "" {
+		o.webhook.Secret = os.Getenv(secretEnv)
+		if o.webhook.Secret == "" {
+			return fmt.Errorf("-webhook-secret-env: environment variable %s is not set", secretEnv)
+		}
+	}
+	o.webhook.Retries = webhookRetries
+	return nil
+}
+
+// sendWebhook POSTs the JSON report to -webhook. A failed delivery fails the run unless
+// -webhook-optional is set. Nothing is sent when the run failed before producing a report.
+func sendWebhook(ctx context.Context, status exitStatus, report *reporter.Report, opts options) exitStatus {
+	if opts.webhook.URL == "" || report == nil {
+		return status
+	}
+
+	var body bytes.Buffer
+	err := report.Write(&body, "json")
+	if err == nil {
+		slog.Info("Delivering report to webhook", "url", opts.webhook.URL)
+		err = webhook.Send(ctx, opts.webhook, body.Bytes())
+	}
+	if err == nil {
+		return status
+	}
+
+	if opts.webhookOptional {
+		slog.Warn("Webhook delivery failed", "url", opts.webhook.URL, "error", err)
+		return status
+	}
+	slog.Error("Webhook delivery failed", "url", opts.webhook.URL, "error", err)
+	return worseExit(status, exitStatus{Code: 2, Reason: reasonWebhookFailed, Count: 1})
+}
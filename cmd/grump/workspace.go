@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/divolgin/grump"
+	"github.com/divolgin/grump/pkg/patcher"
+	"golang.org/x/mod/modfile"
+)
+
+// findWorkspace returns the go.work file to process for path, or "" if path is a single module.
+// A go.work path is used as is. A directory is treated as a workspace when it has a go.work but
+// no go.mod of its own, or whenever force (-workspace) is set.
+func findWorkspace(path string, force bool) (string, error) {
+	if filepath.Base(path) == "go.work" {
+		return path, nil
+	}
+
+	goWork := filepath.Join(path, "go.work")
+	if _, err := os.Stat(goWork); err != nil {
+		if force {
+			return "", fmt.Errorf("go.work not found at %s", goWork)
+		}
+		return "", nil
+	}
+	if force {
+		return goWork, nil
+	}
+	if _, err := os.Stat(filepath.Join(path, "go.mod")); errors.Is(err, os.ErrNotExist) {
+		return goWork, nil
+	}
+	return "", nil
+}
+
+// workspaceModules returns the go.mod files of the modules listed in a go.work's use directives
+func workspaceModules(goWorkPath string) ([]string, error) {
+	data, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.work: %w", err)
+	}
+	work, err := modfile.ParseWork(goWorkPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.work: %w", err)
+	}
+
+	root := filepath.Dir(goWorkPath)
+	var goMods []string
+	for _, use := range work.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		goMods = append(goMods, filepath.Join(dir, "go.mod"))
+	}
+	return goMods, nil
+}
+
+// runWorkspace scans and patches every member of a go.work workspace like -recursive does,
+// then runs go work sync so the members agree on the patched dependency versions
+func runWorkspace(ctx context.Context, runner *grump.Runner, goWorkPath string, opts options) exitStatus {
+	goMods, err := workspaceModules(goWorkPath)
+	if err != nil {
+		slog.Error("Failed to read workspace", "error", err)
+		return statusError
+	}
+	if len(goMods) == 0 {
+		slog.Error("Workspace has no use directives", "workspace", goWorkPath)
+		return statusError
+	}
+
+	root := filepath.Dir(goWorkPath)
+	modules, status := runModules(ctx, runner, root, goMods, opts)
+
+	// Members are patched independently; syncing pushes the workspace build list back into them
+	if !opts.DryRun {
+		if err := patcher.SyncWorkspace(root, opts.Env...); err != nil {
+			slog.Warn("go work sync failed; run it manually to keep the workspace consistent", "error", err)
+		}
+	}
+
+	return writeModules(modules, status, opts)
+}
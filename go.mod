@@ -9,6 +9,7 @@ require (
 	github.com/anchore/grype v0.101.1
 	github.com/anchore/syft v1.34.2
 	github.com/chainguard-dev/gobump v0.9.3
+	github.com/wagoodman/go-partybus v0.0.0-20230516145632-8ccac152c651
 	golang.org/x/mod v0.29.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -256,7 +257,6 @@ require (
 	github.com/vbatts/go-mtree v0.6.0 // indirect
 	github.com/vbatts/tar-split v0.12.1 // indirect
 	github.com/vifraa/gopom v1.0.0 // indirect
-	github.com/wagoodman/go-partybus v0.0.0-20230516145632-8ccac152c651 // indirect
 	github.com/wagoodman/go-progress v0.0.0-20230925121702-07e42b3cdba0 // indirect
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
 	github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8 // indirect
@@ -0,0 +1,596 @@
+// Package grump scans a Go module for vulnerable dependencies and patches them.
+// It is the pipeline behind the grump command, usable from other Go programs.
+package grump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/divolgin/grump/pkg/config"
+	"github.com/divolgin/grump/pkg/kev"
+	"github.com/divolgin/grump/pkg/osv"
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/progress"
+	"github.com/divolgin/grump/pkg/reporter"
+	"github.com/divolgin/grump/pkg/scanner"
+	"github.com/divolgin/grump/pkg/state"
+)
+
+// ErrWouldCreateGoSum is returned when the project has no go.sum, patching would create one,
+// and Options.AllowCreateGoSum is not set
+var ErrWouldCreateGoSum = errors.New("go.sum not found and patching would create it")
+
+// Options configures a grump run. The zero value scans and patches with default settings.
+type Options struct {
+	// GoModPath is the go.mod of the module to scan and patch. It may be empty when
+	// SBOMPath is set, in which case findings are reported but nothing is patched.
+	GoModPath string
+	// SBOMPath is a prebuilt syft SBOM to match instead of cataloging the module
+	SBOMPath string
+	// BinaryPath is a compiled Go binary to scan instead of a module. Binaries can't be
+	// patched, so the run is report-only and GoModPath is ignored.
+	BinaryPath string
+	// RemoteURL is the git repository the module was cloned from, for display. A temporary
+	// clone isn't worth patching, so the run is report-only.
+	RemoteURL string
+	// GrypeConfigPath is a grype config file with ignore rules
+	GrypeConfigPath string
+	// IgnoreRules are added to the rules from GrypeConfigPath
+	IgnoreRules []match.IgnoreRule
+	// IgnoreVulns are vulnerability IDs, or glob patterns, to leave out of the results. A
+	// .grumpignore file in the module directory adds to them, see config.LoadIgnoreFile.
+	IgnoreVulns    []string
+	NormalizeByCVE bool
+	MinSeverity    string
+	// AlwaysFix are module patterns fixed regardless of MinSeverity, see scanner.Scanner.SetAlwaysFix
+	AlwaysFix []string
+	// SeverityOverrides maps vulnerability IDs to the severity to report them with, see
+	// scanner.Scanner.SetSeverityOverrides
+	SeverityOverrides map[string]string
+	FixStrategy       scanner.FixVersionStrategy
+	// FixStates limits the findings to these fix states, see scanner.Scanner.SetFixStates
+	FixStates []vulnerability.FixState
+	// GoOnly matches Go modules only, skipping the matchers of other ecosystems
+	GoOnly bool
+	// AutoSync runs go mod download before scanning when go.sum is missing entries go.mod needs
+	AutoSync bool
+	// Env are extra KEY=VALUE variables for the go commands run while patching, see
+	// patcher.Patcher.SetEnv
+	Env []string
+	// MaxPasses is how many times modules may be patched in a row when a fix version is itself
+	// vulnerable. Each pass after the first re-scans the project; 0 and 1 patch once.
+	MaxPasses int
+	// RequireInGoMod drops updates for modules the go.mod require block doesn't list
+	RequireInGoMod bool
+	// CacheDir caches SBOMs keyed by go.sum so unchanged modules aren't cataloged again, see
+	// scanner.Scanner.SetSBOMCacheDir
+	CacheDir string
+	// IncludeOSPackages also reports vulnerabilities in packages that aren't Go modules, such
+	// as distro packages in the project directory. They are never patched.
+	IncludeOSPackages bool
+	// ExcludePaths are glob patterns of paths left out of cataloging, see
+	// scanner.Scanner.SetExcludePaths
+	ExcludePaths []string
+	// DBMaxAge reuses an installed vulnerability database built within this long instead of
+	// checking for updates; 0 always checks
+	DBMaxAge time.Duration
+	// DBRetries is how many times loading the vulnerability database is retried after a
+	// network error
+	DBRetries int
+	// Offline uses the installed vulnerability database as is, without checking for updates
+	Offline bool
+	// Since keeps only fixable vulnerabilities published at or after this time, see
+	// scanner.FilterSince. StrictSince also drops those without a known publish date, and
+	// SinceOSV looks up the dates missing from the vulnerability database on OSV.dev.
+	Since       time.Time
+	StrictSince bool
+	SinceOSV    bool
+	// DBDir is the directory of the vulnerability database, grype's default when empty
+	DBDir string
+	// Timeout limits how long scanning and patching the module may take; 0 means no limit.
+	// Patching stops between packages, and the packages it didn't reach are reported as failed.
+	Timeout     time.Duration
+	EscalateKEV bool
+	// StatePath enables incremental mode, see state.Advance
+	StatePath string
+	// BaselinePath is a JSON report from an earlier run to compare the findings against
+	BaselinePath string
+
+	PatchPrefixes []string
+	MinConfidence string
+	// MaxSeverity leaves fixes above this severity for manual review, see patcher.Policy
+	MaxSeverity string
+	// OnlyDirect leaves indirect dependencies to be fixed upstream, see patcher.Policy
+	OnlyDirect       bool
+	DryRun           bool
+	AllowCreateGoSum bool
+	GetFallback      bool
+	NoTidy           bool
+	RespectMajor     bool
+	VerifyVersions   bool
+	VerifyBuild      bool
+	RollbackBroken   bool
+	CheckLatest      bool
+	GoVersions       []string
+	EmbedGoMod       bool
+	// ShowDiff appends a unified diff of the go.mod and go.sum changes to the report
+	ShowDiff bool
+	// Confirm, when set, approves each update before it is applied, see patcher.Patcher.SetConfirm
+	Confirm func(scanner.PackageUpdate) bool
+
+	// Progress prints the progress of cataloging and database updates to stderr
+	Progress bool
+
+	// Verbose, Explain, SummaryOnly, Metadata, View, SortBy, Version, and Template configure
+	// how the report is rendered
+	Verbose     bool
+	Explain     bool
+	SummaryOnly bool
+	Metadata    map[string]string
+	View        string
+	SortBy      string
+	Version     string
+	Template    string
+}
+
+// patchPolicy builds the patcher policy from the options
+func (o Options) patchPolicy() patcher.Policy {
+	return patcher.Policy{
+		AllowedPrefixes: o.PatchPrefixes,
+		MinConfidence:   o.MinConfidence,
+		MaxSeverity:     o.MaxSeverity,
+		OnlyDirect:      o.OnlyDirect,
+	}
+}
+
+// Runner runs the grump pipeline with a loaded vulnerability database, so that several
+// modules can be processed without reloading it
+type Runner struct {
+	opts     Options
+	scan     *scanner.Scanner
+	progress *progress.Monitor
+}
+
+// NewRunner loads the vulnerability database and configures the scanner
+func NewRunner(opts Options) (_ *Runner, err error) {
+	// Progress must be wired up before the database is loaded to report its download
+	var monitor *progress.Monitor
+	if opts.Progress {
+		monitor = progress.Start(os.Stderr)
+		defer func() {
+			if err != nil {
+				monitor.Stop()
+			}
+		}()
+	}
+
+	slog.Info("Initializing vulnerability scanner")
+	scan, err := scanner.New(scanner.Options{
+		GrypeConfigPath:    opts.GrypeConfigPath,
+		NormalizeByCVE:     opts.NormalizeByCVE,
+		DBMaxAge:           opts.DBMaxAge,
+		DBRetries:          opts.DBRetries,
+		Offline:            opts.Offline,
+		DBDir:              opts.DBDir,
+		GoOnly:             opts.GoOnly,
+		FixVersionStrategy: opts.FixStrategy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	// A runner that isn't returned can't be closed by the caller
+	defer func() {
+		if err != nil {
+			scan.Close()
+		}
+	}()
+	if built := scan.DBBuilt(); opts.Progress && !built.IsZero() {
+		slog.Info("Loaded vulnerability database",
+			"built", built.UTC().Format(time.RFC3339), "age", time.Since(built).Round(time.Minute).String())
+	}
+	if err := scan.SetMinSeverity(opts.MinSeverity); err != nil {
+		return nil, err
+	}
+	if err := scan.SetAlwaysFix(opts.AlwaysFix); err != nil {
+		return nil, err
+	}
+	if err := scan.SetSeverityOverrides(opts.SeverityOverrides); err != nil {
+		return nil, err
+	}
+	if err := scan.SetExcludePaths(opts.ExcludePaths); err != nil {
+		return nil, err
+	}
+	scan.SetRequireInGoMod(opts.RequireInGoMod)
+	scan.SetSBOMCacheDir(opts.CacheDir)
+	scan.SetIncludeOSPackages(opts.IncludeOSPackages)
+	if err := scan.SetFixStates(opts.FixStates); err != nil {
+		return nil, err
+	}
+	scan.AddIgnoreRules(opts.IgnoreRules...)
+
+	// Escalate severity using real-world exploitation data, before -min-severity applies
+	if opts.EscalateKEV {
+		var catalog *kev.Catalog
+		cacheDir, err := kev.DefaultCacheDir()
+		if err == nil {
+			catalog, err = kev.Load(cacheDir, kev.DefaultMaxAge)
+		}
+		if err != nil {
+			// Degrade to the vulnerability database's own exploitation data
+			slog.Warn("KEV catalog unavailable, using vulnerability database data only", "error", err)
+		}
+		scan.SetEscalation(func(updates []scanner.PackageUpdate) {
+			kev.Escalate(updates, catalog)
+		})
+	}
+
+	return &Runner{opts: opts, scan: scan, progress: monitor}, nil
+}
+
+// Clone returns a runner sharing the loaded database that can run concurrently with this one
+func (r *Runner) Clone() *Runner {
+	return &Runner{opts: r.opts, scan: r.scan.Clone()}
+}
+
+// Scanner returns the configured scanner, for diagnostics such as the loaded database
+func (r *Runner) Scanner() *scanner.Scanner {
+	return r.scan
+}
+
+// Close releases the scanner's resources and stops progress reporting
+func (r *Runner) Close() {
+	r.scan.Close()
+	if r.progress != nil {
+		r.progress.Stop()
+	}
+}
+
+// Run scans and patches the module at opts.GoModPath and returns the report
+func Run(opts Options) (*reporter.Report, error) {
+	runner, err := NewRunner(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer runner.Close()
+
+	return runner.RunModule(context.Background(), opts.GoModPath)
+}
+
+// RunModule scans and patches the module whose go.mod is at goModPath and returns the report.
+// An empty goModPath is allowed with Options.SBOMPath, and goModPath is ignored with
+// Options.BinaryPath.
+func (r *Runner) RunModule(ctx context.Context, goModPath string) (*reporter.Report, error) {
+	opts := r.opts
+	scan := r.scan
+	start := time.Now()
+	var err error
+
+	// Load the baseline first so a bad path fails before anything is patched
+	var baseline *reporter.Report
+	if opts.BaselinePath != "" {
+		baseline, err = reporter.LoadReport(opts.BaselinePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Exclusions versioned with the project add to those given in the options
+	ignoreVulns := opts.IgnoreVulns
+	var ignoreModules []string
+	if opts.BinaryPath == "" && goModPath != "" {
+		ignore, err := config.LoadIgnoreFile(filepath.Dir(goModPath))
+		if err != nil {
+			return nil, err
+		}
+		ignoreVulns = append(slices.Clip(ignoreVulns), ignore.Vulnerabilities...)
+		ignoreModules = ignore.Modules
+	}
+	scan.SetIgnoredVulnerabilities(ignoreVulns)
+	scan.SetIgnoredModules(ignoreModules)
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// Scan the project, or match a prebuilt SBOM against the current database
+	var matches match.Matches
+	if opts.BinaryPath != "" {
+		// A binary can't be patched, so there is no project to bind to
+		goModPath = ""
+		slog.Info("Scanning binary for vulnerabilities", "binary", opts.BinaryPath)
+		matches, _, err = scan.ScanBinary(ctx, opts.BinaryPath)
+	} else if opts.SBOMPath != "" {
+		slog.Info("Matching SBOM for vulnerabilities", "sbom", opts.SBOMPath)
+		var packages []pkg.Package
+		matches, packages, err = scan.ScanSBOM(opts.SBOMPath)
+		if err == nil && goModPath != "" {
+			err = scan.BindModule(goModPath, packages)
+		}
+	} else {
+		checkModuleSync(filepath.Dir(goModPath), opts)
+		slog.Info("Scanning project for vulnerabilities", "gomod", goModPath)
+		matches, _, err = scan.ScanWithContext(ctx, goModPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+	// Follow-up passes scan again, so keep the timings of the initial scan for the report
+	scanTimings := scan.Timings()
+
+	// Get fixable updates, escalated with exploitation data when enabled, see NewRunner
+	updates := scan.GetFixableUpdates(matches)
+
+	// Focus on recently disclosed vulnerabilities
+	if !opts.Since.IsZero() {
+		if opts.SinceOSV {
+			cacheDir, err := kev.DefaultCacheDir()
+			if err != nil {
+				return nil, err
+			}
+			dates, err := osv.LoadDates(cacheDir, !opts.Offline)
+			if err != nil {
+				return nil, err
+			}
+			dates.Fill(updates)
+			if err := dates.Save(); err != nil {
+				slog.Warn("Failed to cache vulnerability publish dates", "error", err)
+			}
+		}
+		updates = scanner.FilterSince(updates, opts.Since, opts.StrictSince)
+	}
+
+	// Advisories against the scanned module itself can't be fixed by bumping a dependency
+	mainModuleUpdates := scan.GetMainModuleUpdates(matches)
+	for _, upd := range mainModuleUpdates {
+		slog.Info("Skipping advisory matching the scanned module itself", "module", upd.Name, "vulnerability", upd.VulnID)
+	}
+
+	// Vulnerabilities without a fix still need to be tracked by a human
+	unfixable := scan.GetUnfixableVulnerabilities(matches)
+
+	// In incremental mode, compare against the previous run and record this one
+	var changes *state.Delta
+	if opts.StatePath != "" {
+		changes, err = advanceState(opts.StatePath, scan.DBSchemaVersion(), updates, unfixable)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var results []patcher.UpdateResult
+	var tidyMessages []patcher.TidyMessage
+	goSumCreated := false
+	tidySkipped := false
+	var moduleFiles *reporter.ModuleFiles
+	var diff string
+	var resolutions []patcher.GoVersionResolution
+	var buildErr error
+	var patchTime time.Duration
+	var fixChains []reporter.FixChain
+	if len(updates) > 0 && (goModPath == "" || opts.RemoteURL != "") {
+		// Scanning a binary, a remote repository, or an SBOM without a project leaves nothing
+		// to patch
+		reason := "no project path given to patch"
+		if opts.BinaryPath != "" {
+			reason = "compiled binaries can't be patched"
+		} else if opts.RemoteURL != "" {
+			reason = "remote repositories are scanned from a temporary clone and not patched"
+		}
+		for _, upd := range updates {
+			results = append(results, patcher.UpdateResult{
+				Update:  upd,
+				Skipped: true,
+				Reason:  reason,
+			})
+		}
+	} else if len(updates) > 0 {
+		// Initialize patcher with the project directory
+		projectDir := filepath.Dir(goModPath)
+		patch, err := patcher.New(projectDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize patcher: %w", err)
+		}
+		if err := patch.SetPolicy(opts.patchPolicy()); err != nil {
+			return nil, err
+		}
+
+		if err := patch.SetEnv(opts.Env); err != nil {
+			return nil, err
+		}
+		patch.SetDryRun(opts.DryRun)
+		patch.SetConfirm(opts.Confirm)
+		patch.SetGetFallback(opts.GetFallback)
+		patch.SetSkipTidy(opts.NoTidy)
+		patch.SetRespectMajor(opts.RespectMajor)
+		patch.SetVerifyVersions(opts.VerifyVersions)
+		// RollbackBroken verifies the build itself and only reverts the offending packages
+		patch.SetVerifyBuild(opts.VerifyBuild && !opts.RollbackBroken && !opts.DryRun)
+
+		// Patching creates go.sum if it's missing; only do that when explicitly allowed
+		hadGoSum := patch.HasGoSum()
+		if !hadGoSum && !opts.AllowCreateGoSum && !opts.DryRun {
+			return nil, fmt.Errorf("%w in %s", ErrWouldCreateGoSum, projectDir)
+		}
+
+		// Dry-run resolution under other toolchains before the project is modified
+		if len(opts.GoVersions) > 0 {
+			slog.Info("Checking update resolution", "go_versions", strings.Join(opts.GoVersions, ","))
+			resolutions = patch.ResolveUnderGoVersions(updates, opts.GoVersions)
+		}
+
+		// Look up newer releases before patching so the report can suggest jumping further
+		if opts.CheckLatest {
+			patch.AnnotateLatest(updates)
+		}
+
+		// Apply updates
+		patchStart := time.Now()
+		var patchErr error
+		results, patchErr = patch.UpdateAllContext(ctx, updates)
+		if errors.Is(patchErr, patcher.ErrRollbackFailed) {
+			// The project is left patched and no longer builds; fail the run rather than report it as patched
+			return nil, patchErr
+		}
+		if patchErr != nil {
+			results = appendNotAttempted(results, updates, patchErr)
+		}
+
+		// A fix version may itself be vulnerable; keep bumping those modules until they converge
+		if opts.MaxPasses > 1 && !opts.DryRun && opts.SBOMPath == "" && patchErr == nil {
+			var found []scanner.PackageUpdate
+			found, results, fixChains, err = r.followUpPasses(ctx, goModPath, patch, results)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, found...)
+		}
+		tidyMessages = patch.TidyMessages()
+		tidySkipped = opts.NoTidy && !opts.DryRun
+
+		// Keep the bumps that build, roll back the ones that don't
+		if opts.RollbackBroken && !opts.DryRun && patchErr == nil {
+			results, err = patch.RollbackBroken(results)
+			if err != nil {
+				return nil, fmt.Errorf("failed to roll back broken updates: %w", err)
+			}
+		}
+		patchTime = time.Since(patchStart)
+
+		buildErr = patch.BuildError()
+
+		if !hadGoSum && patch.HasGoSum() {
+			goSumCreated = true
+			slog.Info("Created go.sum", "dir", projectDir)
+		}
+
+		if opts.EmbedGoMod {
+			after, err := patch.Current()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read patched module files: %w", err)
+			}
+			before := patch.Original()
+			moduleFiles = &reporter.ModuleFiles{
+				Before: reporter.ModuleFileContents{GoMod: string(before.GoMod), GoSum: string(before.GoSum)},
+				After:  reporter.ModuleFileContents{GoMod: string(after.GoMod), GoSum: string(after.GoSum)},
+			}
+		}
+
+		if opts.ShowDiff {
+			diff, err = patch.DiffGoMod()
+			if err != nil {
+				return nil, fmt.Errorf("failed to diff patched module files: %w", err)
+			}
+		}
+	}
+
+	// Assemble the report; rendering is up to the caller
+	rep := reporter.New(nil)
+	rep.Verbose = opts.Verbose
+	rep.Explain = opts.Explain
+	rep.SummaryOnly = opts.SummaryOnly
+	rep.TidyMessages = tidyMessages
+	rep.MainModuleUpdates = mainModuleUpdates
+	rep.Metadata = opts.Metadata
+	rep.Unfixable = unfixable
+	if opts.IncludeOSPackages {
+		rep.OSVulnerabilities = scan.GetOSVulnerabilities(matches)
+	}
+	rep.View = opts.View
+	rep.SortBy = opts.SortBy
+	rep.GoSumCreated = goSumCreated
+	rep.TidySkipped = tidySkipped
+	rep.ModuleFiles = moduleFiles
+	rep.Diff = diff
+	rep.Resolutions = resolutions
+	rep.Changes = changes
+	rep.Baseline = baseline
+	rep.BaselinePath = opts.BaselinePath
+	rep.DryRun = opts.DryRun
+	rep.ReportOnly = opts.BinaryPath != "" || opts.RemoteURL != ""
+	rep.RemoteURL = opts.RemoteURL
+	rep.Version = opts.Version
+	rep.Template = opts.Template
+	rep.BuildError = buildErr
+	rep.FixChains = fixChains
+	// The database is loaded once per runner, so it counts towards every module's total
+	rep.Timings = reporter.NewTimings(scanTimings.DBLoad, scanTimings.SBOM, scanTimings.Match, patchTime,
+		scanTimings.DBLoad+time.Since(start))
+
+	return rep.BuildReport(updates, results), nil
+}
+
+// checkModuleSync warns when go.mod and go.sum in dir are out of sync, which makes the SBOM
+// incomplete and patching fail. With opts.AutoSync it runs go mod download to add the missing
+// go.sum entries first. Projects without a go.sum are left to the go.sum checks when patching.
+func checkModuleSync(dir string, opts Options) {
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); err != nil {
+		return
+	}
+
+	err := patcher.CheckSync(dir, opts.Env...)
+	if errors.Is(err, patcher.ErrOutOfSync) && opts.AutoSync {
+		if opts.DryRun {
+			slog.Warn("Not running go mod download in a dry run", "dir", dir)
+		} else {
+			slog.Info("Running go mod download to sync go.sum", "dir", dir)
+			if err := patcher.DownloadModules(dir, opts.Env...); err != nil {
+				slog.Warn("go mod download failed", "error", err)
+			}
+			err = patcher.CheckSync(dir, opts.Env...)
+		}
+	}
+
+	switch {
+	case errors.Is(err, patcher.ErrOutOfSync):
+		slog.Warn("go.mod and go.sum are out of sync; findings may be incomplete and patching may fail. Run go mod tidy, or re-run with -auto-sync",
+			"dir", dir, "error", err)
+	case err != nil:
+		slog.Debug("Could not check whether go.mod and go.sum are in sync", "dir", dir, "error", err)
+	}
+}
+
+// appendNotAttempted adds a failed result with err for each update whose package patching
+// didn't reach, so interrupted updates are reported as failed rather than silently pending
+func appendNotAttempted(results []patcher.UpdateResult, updates []scanner.PackageUpdate, err error) []patcher.UpdateResult {
+	attempted := make(map[string]bool, len(results))
+	for _, result := range results {
+		attempted[result.Update.Name] = true
+	}
+	for _, upd := range scanner.CoalesceUpdates(updates) {
+		if !attempted[upd.Name] {
+			results = append(results, patcher.UpdateResult{Update: upd, Error: err})
+		}
+	}
+	return results
+}
+
+// advanceState loads the previous run's state, computes the changes since then, and records
+// the current findings. A database schema change resets the state.
+func advanceState(path, dbSchemaVersion string, updates []scanner.PackageUpdate, unfixable []scanner.UnfixableVulnerability) (*state.Delta, error) {
+	prev, err := state.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.DBSchemaVersion != dbSchemaVersion {
+		slog.Warn("Vulnerability database schema changed, resetting state",
+			"from", prev.DBSchemaVersion, "to", dbSchemaVersion, "state", path)
+	}
+
+	changes, next := state.Advance(prev, state.FromScan(updates, unfixable), dbSchemaVersion)
+	if err := next.Save(path); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,133 @@
+package grump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/reporter"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// followUpPasses re-scans the patched module and patches again the modules whose fix version
+// turned out to be vulnerable itself, until nothing patched is vulnerable or opts.MaxPasses
+// passes have run. The updates fixed in later passes are returned for the report, each
+// module's results are merged into one spanning all its bumps, and the modules bumped more
+// than once, or still vulnerable, are returned as fix chains. A module whose later bump fails
+// keeps the earlier one, with the vulnerabilities it still has recorded in its chain.
+func (r *Runner) followUpPasses(ctx context.Context, goModPath string, patch *patcher.Patcher,
+	results []patcher.UpdateResult) ([]scanner.PackageUpdate, []patcher.UpdateResult, []reporter.FixChain, error) {
+	chains := make(map[string]*reporter.FixChain)
+	following := make(map[string]bool)
+	var order []string
+	for _, result := range results {
+		if result.Success {
+			chains[result.Update.Name] = &reporter.FixChain{
+				Package:   result.Update.Name,
+				Versions:  []string{result.Update.CurrentVersion, result.Update.TargetVersion},
+				Converged: true,
+			}
+			following[result.Update.Name] = true
+			order = append(order, result.Update.Name)
+		}
+	}
+
+	var found []scanner.PackageUpdate
+	for pass := 2; len(following) > 0; pass++ {
+		matches, _, err := r.scan.ScanWithContext(ctx, goModPath)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to re-scan after pass %d: %w", pass-1, err)
+		}
+
+		// Only the modules this run patched are followed; anything else was already reported
+		var next []scanner.PackageUpdate
+		for _, upd := range r.scan.GetFixableUpdates(matches) {
+			if following[upd.Name] {
+				next = append(next, upd)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		if pass > r.opts.MaxPasses {
+			for _, upd := range next {
+				chain := chains[upd.Name]
+				chain.Converged = false
+				chain.Remaining = append(chain.Remaining, upd.VulnID)
+				slog.Warn("Fix version is still vulnerable after the last pass", "module", upd.Name,
+					"version", upd.CurrentVersion, "vulnerability", upd.VulnID, "max_passes", r.opts.MaxPasses)
+			}
+			break
+		}
+
+		slog.Info("Fix versions are vulnerable, patching again", "pass", pass, "updates", len(next))
+		passResults, err := patch.UpdateAllContext(ctx, next)
+		if errors.Is(err, patcher.ErrRollbackFailed) {
+			// The project is left patched and no longer builds, the same failure as in the first pass
+			return nil, nil, nil, err
+		}
+		if err != nil {
+			passResults = appendNotAttempted(passResults, next, err)
+		}
+
+		fixed := make(map[string]bool)
+		for _, result := range passResults {
+			chain := chains[result.Update.Name]
+			if !result.Success {
+				// go.mod keeps the earlier bump, which is still vulnerable; stop following the module
+				chain.Converged = false
+				chain.Remaining = append(chain.Remaining, vulnIDs(result.Update)...)
+				delete(following, result.Update.Name)
+				slog.Warn("Failed to patch a vulnerable fix version again", "module", result.Update.Name,
+					"version", result.Update.CurrentVersion, "pass", pass, "error", result.Error, "reason", result.Reason)
+				continue
+			}
+			fixed[result.Update.Name] = true
+			chain.Versions = append(chain.Versions, result.Update.TargetVersion)
+			results = mergeResult(results, result)
+		}
+		for _, upd := range next {
+			if fixed[upd.Name] {
+				found = append(found, upd)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	var fixChains []reporter.FixChain
+	for _, name := range order {
+		if chain := chains[name]; len(chain.Versions) > 2 || !chain.Converged {
+			fixChains = append(fixChains, *chain)
+		}
+	}
+	return found, results, fixChains, nil
+}
+
+// mergeResult replaces the result for the same module with a successful one from a later
+// pass. The merged result keeps the version from before the first pass and every
+// vulnerability resolved.
+func mergeResult(results []patcher.UpdateResult, later patcher.UpdateResult) []patcher.UpdateResult {
+	for i, earlier := range results {
+		if earlier.Update.Name != later.Update.Name {
+			continue
+		}
+		later.Update.VulnIDs = append(vulnIDs(earlier.Update), vulnIDs(later.Update)...)
+		later.Update.CurrentVersion = earlier.Update.CurrentVersion
+		results[i] = later
+		return results
+	}
+	return append(results, later)
+}
+
+// vulnIDs returns the vulnerabilities an update resolves, whether or not it was coalesced
+func vulnIDs(upd scanner.PackageUpdate) []string {
+	if len(upd.VulnIDs) == 0 {
+		return []string{upd.VulnID}
+	}
+	return append([]string(nil), upd.VulnIDs...)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/anchore/grype/grype/match"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds grump options read from a config file. Keys are named after the
+// corresponding command line flags; JSON files are accepted as well as YAML.
+type Config struct {
+	Format      string `yaml:"format"`
+	MinSeverity string `yaml:"min-severity"`
+	FixStrategy string `yaml:"fix-strategy"`
+	// Timeout is a Go duration string, e.g. "5m"
+	Timeout string `yaml:"timeout"`
+	// Ignore uses the same rule format as the ignore section of a grype config file
+	Ignore []match.IgnoreRule `yaml:"ignore"`
+	// SeverityOverrides maps vulnerability IDs to the severity to report them with
+	SeverityOverrides map[string]string `yaml:"severity-overrides"`
+
+	timeout time.Duration
+}
+
+// Load reads and validates the config file at path. Unknown keys are rejected.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %w (supported keys: format, min-severity, fix-strategy, timeout, ignore, severity-overrides)", path, err)
+	}
+
+	if cfg.Timeout != "" {
+		cfg.timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %s: timeout: %w", path, err)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// TimeoutDuration returns the parsed timeout, 0 if unset
+func (c *Config) TimeoutDuration() time.Duration {
+	return c.timeout
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the name of the ignore file LoadIgnoreFile reads from a project root
+const IgnoreFileName = ".grumpignore"
+
+// vulnIDPrefixes identify ignore file lines that are vulnerability IDs rather than module paths
+var vulnIDPrefixes = []string{"CVE-", "GHSA-", "GO-"}
+
+// IgnoreFile holds the exclusions listed in a .grumpignore file
+type IgnoreFile struct {
+	// Modules are module path patterns: an exact path, a prefix ending in "/*", or a glob
+	Modules []string
+	// Vulnerabilities are vulnerability ID patterns such as "CVE-2024-1234" or "GHSA-*"
+	Vulnerabilities []string
+}
+
+// LoadIgnoreFile reads the .grumpignore file in dir. Each line is a module path or a
+// vulnerability ID, either of which may be a glob pattern; lines starting with "#" and
+// text after " #" are comments. A missing file yields an empty IgnoreFile.
+func LoadIgnoreFile(dir string) (*IgnoreFile, error) {
+	name := filepath.Join(dir, IgnoreFileName)
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &IgnoreFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	defer f.Close()
+
+	ignore := &IgnoreFile{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid ignore file %s:%d: pattern %q: %w", name, lineNo, line, err)
+		}
+		if isVulnIDPattern(line) {
+			ignore.Vulnerabilities = append(ignore.Vulnerabilities, line)
+		} else {
+			ignore.Modules = append(ignore.Modules, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	return ignore, nil
+}
+
+// isVulnIDPattern reports whether an ignore file entry is a vulnerability ID pattern.
+// Module paths always contain a "/" or a ".", which vulnerability IDs never do.
+func isVulnIDPattern(entry string) bool {
+	if strings.ContainsAny(entry, "/.") {
+		return false
+	}
+	upper := strings.ToUpper(entry)
+	for _, prefix := range vulnIDPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package kev
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// FeedURL is the CISA Known Exploited Vulnerabilities catalog
+const FeedURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// DefaultMaxAge is how long a cached copy of the catalog is used before refetching
+const DefaultMaxAge = 24 * time.Hour
+
+// EPSSThreshold is the exploit probability at or above which a vulnerability is escalated to High
+const EPSSThreshold = 0.5
+
+// Catalog is a set of CVE IDs known to be exploited in the wild
+type Catalog struct {
+	cves map[string]bool
+}
+
+// feed is the subset of the CISA catalog JSON used by grump
+type feed struct {
+	Vulnerabilities []struct {
+		CVEID string `json:"cveID"`
+	} `json:"vulnerabilities"`
+}
+
+// Load returns the KEV catalog, using a cached copy in cacheDir if it's newer than maxAge.
+// If the feed can't be fetched, a stale cached copy is used when available.
+func Load(cacheDir string, maxAge time.Duration) (*Catalog, error) {
+	cachePath := filepath.Join(cacheDir, "known_exploited_vulnerabilities.json")
+
+	if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < maxAge {
+		if catalog, err := readCatalog(cachePath); err == nil {
+			return catalog, nil
+		}
+	}
+
+	if err := download(cachePath); err != nil {
+		if catalog, cacheErr := readCatalog(cachePath); cacheErr == nil {
+			slog.Warn("Failed to refresh KEV catalog, using cached copy", "error", err)
+			return catalog, nil
+		}
+		return nil, err
+	}
+
+	return readCatalog(cachePath)
+}
+
+// DefaultCacheDir returns the directory grump caches the KEV catalog in
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "grump"), nil
+}
+
+// download fetches the feed and writes it to path
+func download(path string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(FeedURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch KEV catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch KEV catalog: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read KEV catalog: %w", err)
+	}
+
+	// Make sure what we cache is parseable
+	var f feed
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse KEV catalog: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write KEV cache: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// readCatalog parses a cached feed file
+func readCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KEV cache: %w", err)
+	}
+
+	var f feed
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV cache: %w", err)
+	}
+
+	catalog := &Catalog{cves: make(map[string]bool, len(f.Vulnerabilities))}
+	for _, v := range f.Vulnerabilities {
+		catalog.cves[v.CVEID] = true
+	}
+	return catalog, nil
+}
+
+// Contains reports whether any of the IDs is in the catalog
+func (c *Catalog) Contains(ids ...string) bool {
+	if c == nil {
+		return false
+	}
+	for _, id := range ids {
+		if c.cves[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// Escalate raises EffectiveSeverity based on exploitation data, leaving Severity untouched:
+// known exploited vulnerabilities (in the catalog or flagged by the vulnerability database)
+// become Critical, and those with an EPSS score of at least EPSSThreshold become at least High.
+func Escalate(updates []scanner.PackageUpdate, catalog *Catalog) {
+	for i := range updates {
+		upd := &updates[i]
+		if upd.EffectiveSeverity == "" {
+			upd.EffectiveSeverity = upd.Severity
+		}
+
+		ids := append([]string{upd.VulnID}, upd.Aliases...)
+		if upd.KnownExploited || catalog.Contains(ids...) {
+			upd.KnownExploited = true
+			upd.EffectiveSeverity = "Critical"
+			continue
+		}
+
+		if upd.EPSS >= EPSSThreshold && scanner.SeverityRank(upd.EffectiveSeverity) < scanner.SeverityRank("High") {
+			upd.EffectiveSeverity = "High"
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package osv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// APIURL is the OSV.dev endpoint vulnerability records are fetched from, by ID
+const APIURL = "https://api.osv.dev/v1/vulns/"
+
+// cacheFileName is the file in the cache directory that holds fetched publish dates
+const cacheFileName = "osv_published.json"
+
+// errNotFound is returned by fetchPublished for IDs OSV.dev has no record of
+var errNotFound = errors.New("vulnerability not found")
+
+// maxConcurrentFetches bounds the OSV.dev requests in flight at once
+const maxConcurrentFetches = 8
+
+// Dates looks up when vulnerabilities were published on OSV.dev, for findings whose record in
+// the vulnerability database has no publish date. Fetched dates are cached; a publish date
+// doesn't change.
+type Dates struct {
+	cachePath string
+	fetch     bool
+	// mu guards published, missing, and dirty while Fill fetches concurrently
+	mu        sync.Mutex
+	published map[string]time.Time
+	// missing are IDs without a record, which aren't looked up again in this run
+	missing map[string]bool
+	dirty   bool
+	client  *http.Client
+	// baseURL is where records are fetched from, APIURL outside of tests
+	baseURL string
+}
+
+// record is the subset of an OSV record used by grump
+type record struct {
+	Published time.Time `json:"published"`
+}
+
+// LoadDates returns the publish date lookup cached in cacheDir. With fetch unset, only cached
+// dates are known, for offline runs.
+func LoadDates(cacheDir string, fetch bool) (*Dates, error) {
+	d := &Dates{
+		cachePath: filepath.Join(cacheDir, cacheFileName),
+		fetch:     fetch,
+		published: make(map[string]time.Time),
+		missing:   make(map[string]bool),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		baseURL:   APIURL,
+	}
+
+	data, err := os.ReadFile(d.cachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read publish date cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &d.published); err != nil {
+		// The cache is only an optimization; start over rather than fail the run
+		slog.Warn("Ignoring unreadable publish date cache", "path", d.cachePath, "error", err)
+		d.published = make(map[string]time.Time)
+	}
+	return d, nil
+}
+
+// Published returns the publish date of the first of the IDs, such as a finding's ID and its
+// aliases, that has a record
+func (d *Dates) Published(ids ...string) (time.Time, bool) {
+	d.mu.Lock()
+	for _, id := range ids {
+		if published, ok := d.published[id]; ok {
+			d.mu.Unlock()
+			return published, true
+		}
+	}
+	d.mu.Unlock()
+	if !d.fetch {
+		return time.Time{}, false
+	}
+
+	for _, id := range ids {
+		d.mu.Lock()
+		missing := d.missing[id]
+		d.mu.Unlock()
+		if missing {
+			continue
+		}
+
+		published, err := d.fetchPublished(id)
+
+		d.mu.Lock()
+		if err != nil {
+			if !errors.Is(err, errNotFound) {
+				slog.Debug("Failed to look up publish date", "vulnerability", id, "error", err)
+			}
+			d.missing[id] = true
+			d.mu.Unlock()
+			continue
+		}
+		d.published[id] = published
+		d.dirty = true
+		d.mu.Unlock()
+		return published, true
+	}
+	return time.Time{}, false
+}
+
+// Fill sets the publish date of the updates that don't have one from the vulnerability
+// database, looking up their IDs and aliases. Up to maxConcurrentFetches lookups run at once.
+func (d *Dates) Fill(updates []scanner.PackageUpdate) {
+	jobs := make(chan *scanner.PackageUpdate)
+	var wg sync.WaitGroup
+	for w := 0; w < maxConcurrentFetches; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for upd := range jobs {
+				if published, ok := d.Published(append([]string{upd.VulnID}, upd.Aliases...)...); ok {
+					upd.Published = published
+				}
+			}
+		}()
+	}
+	for i := range updates {
+		if updates[i].Published.IsZero() {
+			jobs <- &updates[i]
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// fetchPublished fetches the publish date of a vulnerability from OSV.dev
+func (d *Dates) fetchPublished(id string) (time.Time, error) {
+	resp, err := d.client.Get(d.baseURL + id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch OSV record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("failed to fetch OSV record: unexpected status %s", resp.Status)
+	}
+
+	var rec record
+	if err := json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse OSV record: %w", err)
+	}
+	if rec.Published.IsZero() {
+		return time.Time{}, errNotFound
+	}
+	return rec.Published, nil
+}
+
+// Save writes newly fetched dates to the cache
+func (d *Dates) Save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.dirty {
+		return nil
+	}
+	data, err := json.Marshal(d.published)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.cachePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(d.cachePath), cacheFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write publish date cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write publish date cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write publish date cache: %w", err)
+	}
+	d.dirty = false
+	return os.Rename(tmp.Name(), d.cachePath)
+}
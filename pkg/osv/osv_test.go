@@ -0,0 +1,85 @@
+package osv
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+func TestFill(t *testing.T) {
+	var inFlight, peak, requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		id := strings.TrimPrefix(r.URL.Path, "/")
+		if !strings.HasPrefix(id, "CVE-") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%q,"published":"2024-03-01T00:00:00Z"}`, id)
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	d, err := LoadDates(cacheDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.baseURL = server.URL + "/"
+
+	known := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	updates := []scanner.PackageUpdate{{Name: "example.com/known", VulnID: "CVE-2020-1", Published: known}}
+	for i := 0; i < 20; i++ {
+		updates = append(updates, scanner.PackageUpdate{
+			Name:    fmt.Sprintf("example.com/m%d", i),
+			VulnID:  fmt.Sprintf("GHSA-%d", i),
+			Aliases: []string{fmt.Sprintf("CVE-2024-%d", i)},
+		})
+	}
+
+	d.Fill(updates)
+
+	if !updates[0].Published.Equal(known) {
+		t.Errorf("date from the database was replaced: %v", updates[0].Published)
+	}
+	want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, upd := range updates[1:] {
+		if !upd.Published.Equal(want) {
+			t.Errorf("%s: published = %v, want %v from its CVE alias", upd.VulnID, upd.Published, want)
+		}
+	}
+	// Each update looks up its GHSA, which OSV.dev doesn't know here, then its CVE
+	if got := requests.Load(); got != 40 {
+		t.Errorf("made %d requests, want 40", got)
+	}
+	if got := peak.Load(); got > maxConcurrentFetches {
+		t.Errorf("%d requests in flight at once, want at most %d", got, maxConcurrentFetches)
+	}
+
+	// Fetched dates are cached for the next run, which then needs no requests
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := LoadDates(cacheDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published, ok := cached.Published("CVE-2024-3"); !ok || !published.Equal(want) {
+		t.Errorf("cached date = %v (found %v), want %v", published, ok, want)
+	}
+}
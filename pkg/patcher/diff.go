@@ -0,0 +1,184 @@
+package patcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the line comparison table of a diff. Changes too large for it are shown
+// as one replaced block, which is still a correct diff, just not a minimal one.
+const maxDiffCells = 4_000_000
+
+// DiffGoMod returns a unified diff of go.mod and go.sum from when the Patcher was created to
+// now, or "" if neither changed
+func (p *Patcher) DiffGoMod() (string, error) {
+	current, err := p.takeSnapshot()
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff("go.mod", string(p.original.GoMod), string(current.GoMod)) +
+		unifiedDiff("go.sum", string(p.original.GoSum), string(current.GoSum)), nil
+}
+
+// diffLine is a line of an edit script: kept (' '), removed ('-'), or added ('+')
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// splitLines splits text into lines without their newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineDiff returns an edit script turning a into b. Common leading and trailing lines are
+// matched directly, and the rest by longest common subsequence.
+func lineDiff(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var script []diffLine
+	for _, line := range a[:prefix] {
+		script = append(script, diffLine{' ', line})
+	}
+	script = append(script, middleDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		script = append(script, diffLine{' ', line})
+	}
+	return script
+}
+
+// middleDiff returns an edit script turning a into b using a longest common subsequence table
+func middleDiff(a, b []string) []diffLine {
+	var script []diffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			script = append(script, diffLine{'-', line})
+		}
+		for _, line := range b {
+			script = append(script, diffLine{'+', line})
+		}
+		return script
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
+
+// unifiedDiff returns the changes from before to after in unified diff format, with name in
+// the file headers, or "" if there are none
+func unifiedDiff(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	script := lineDiff(splitLines(before), splitLines(after))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+
+	// aLine and bLine are the line numbers, from 0, each script entry starts at
+	aLine := make([]int, len(script)+1)
+	bLine := make([]int, len(script)+1)
+	for k, line := range script {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if line.op != '+' {
+			aLine[k+1]++
+		}
+		if line.op != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(script); {
+		if script[k].op == ' ' {
+			k++
+			continue
+		}
+
+		// Extend the hunk over changes separated by at most twice the context
+		start := max(k-diffContext, 0)
+		end := k
+		for end < len(script) {
+			if script[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(script) && script[next].op == ' ' {
+				next++
+			}
+			if next == len(script) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		end = min(end+diffContext, len(script))
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]), hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, line := range script[start:end] {
+			fmt.Fprintf(&out, "%c%s\n", line.op, line.text)
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the start line and length of one side of a hunk
+func hunkRange(start, length int) string {
+	if length == 0 {
+		// An empty range names the line before it
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,79 @@
+package patcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// envMu guards the process environment. withEnv holds it exclusively while the patcher's
+// variables are set in it, and go commands, including the ones gobump starts for a patcher
+// without variables, read the environment under it, so the variables of one patcher never
+// leak into the commands of another running concurrently.
+var envMu sync.RWMutex
+
+// SetEnv adds KEY=VALUE environment variables to the go commands the patcher runs, on top of
+// the process environment, which they inherit as is. Use it for settings such as GOPROXY,
+// GOPRIVATE, GONOSUMDB, GONOSUMCHECK, or GOFLAGS that only patching needs, e.g. to reach a
+// private module proxy.
+func (p *Patcher) SetEnv(env []string) error {
+	if err := ValidateEnv(env); err != nil {
+		return err
+	}
+	p.env = env
+	return nil
+}
+
+// ValidateEnv checks that each entry has the form KEY=VALUE
+func ValidateEnv(env []string) error {
+	for _, kv := range env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return fmt.Errorf("invalid environment variable %q: must be KEY=VALUE", kv)
+		}
+	}
+	return nil
+}
+
+// environ returns the process environment without the variables of a concurrent withEnv
+func environ() []string {
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return os.Environ()
+}
+
+// goCommand returns a go command to run in dir with the patcher's environment
+func (p *Patcher) goCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(environ(), p.env...)
+	return cmd
+}
+
+// withEnv runs fn with the patcher's environment variables set in the process environment,
+// restoring it afterwards. gobump runs go with the process environment and can't be given
+// one, so updates with extra variables run one at a time, and the go commands of other
+// patchers, gobump's included, wait for them to finish before they start. fn must not call
+// goCommand.
+func (p *Patcher) withEnv(fn func() error) error {
+	if len(p.env) == 0 {
+		// Without variables of its own, gobump's go commands still mustn't see another patcher's
+		envMu.RLock()
+		defer envMu.RUnlock()
+		return fn()
+	}
+
+	envMu.Lock()
+	defer envMu.Unlock()
+	for _, kv := range p.env {
+		key, value, _ := strings.Cut(kv, "=")
+		if prev, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, prev)
+		} else {
+			defer os.Unsetenv(key)
+		}
+		os.Setenv(key, value)
+	}
+	return fn()
+}
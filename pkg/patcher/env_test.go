@@ -0,0 +1,71 @@
+package patcher
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithEnvDoesNotLeak(t *testing.T) {
+	withVars := &Patcher{env: []string{"GRUMP_TEST_ENV=patching"}}
+	other := &Patcher{}
+
+	inside := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- withVars.withEnv(func() error {
+			close(inside)
+			// Give the other patcher time to start a command while the variable is set
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+	}()
+
+	<-inside
+	cmd := other.goCommand(t.TempDir(), "version")
+	for _, kv := range cmd.Environ() {
+		if strings.HasPrefix(kv, "GRUMP_TEST_ENV=") {
+			t.Errorf("command of another patcher got %s", kv)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The patcher's own commands get its variables
+	if env := withVars.goCommand(t.TempDir(), "version").Env; env[len(env)-1] != "GRUMP_TEST_ENV=patching" {
+		t.Errorf("command env ends with %q, want GRUMP_TEST_ENV=patching", env[len(env)-1])
+	}
+}
+
+func TestWithEnvWithoutVariablesDoesNotLeak(t *testing.T) {
+	withVars := &Patcher{env: []string{"GRUMP_TEST_ENV=patching"}}
+	other := &Patcher{}
+
+	inside := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- withVars.withEnv(func() error {
+			close(inside)
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+	}()
+
+	// gobump of a patcher without variables reads the process environment directly
+	<-inside
+	var leaked bool
+	if err := other.withEnv(func() error {
+		_, leaked = os.LookupEnv("GRUMP_TEST_ENV")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if leaked {
+		t.Error("update of another patcher ran with GRUMP_TEST_ENV set")
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
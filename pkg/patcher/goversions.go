@@ -0,0 +1,231 @@
+package patcher
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/scanner"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// GoVersionResolution is the outcome of resolving one update under one Go toolchain version
+type GoVersionResolution struct {
+	GoVersion string
+	Package   string
+	Target    string
+	// Resolved is the version MVS selected for Package, empty if resolution failed
+	Resolved string
+	// Downgrades lists other modules whose selected version went down, as "path old -> new"
+	Downgrades []string
+	Error      error
+}
+
+// Effective reports whether the bump resolved to at least the target without downgrading anything
+func (r GoVersionResolution) Effective() bool {
+	return r.Error == nil && r.Resolved != "" && semver.Compare(r.Resolved, r.Target) >= 0 && len(r.Downgrades) == 0
+}
+
+// ToolchainName converts a Go version such as "1.22" or "1.22.3" to a GOTOOLCHAIN value.
+// Toolchain selection is only available for Go 1.21 and later.
+func ToolchainName(goVersion string) (string, error) {
+	v := "v" + strings.TrimPrefix(goVersion, "go")
+	if !semver.IsValid(v) || semver.Prerelease(v) != "" {
+		return "", fmt.Errorf("invalid Go version %q", goVersion)
+	}
+	if semver.Compare(v, "v1.21") < 0 {
+		return "", fmt.Errorf("go version %q is not supported: toolchain selection requires Go 1.21 or later", goVersion)
+	}
+
+	// Go 1.21+ toolchains are released as go1.N.0; a bare "1.N" means the first release
+	if strings.Count(v, ".") == 1 {
+		return "go" + strings.TrimPrefix(v, "v") + ".0", nil
+	}
+	return "go" + strings.TrimPrefix(v, "v"), nil
+}
+
+// ResolveUnderGoVersions dry-runs the updates under each Go toolchain version and reports how
+// each bump resolved. MVS results can differ between versions (e.g. graph pruning), so a bump
+// that works under one toolchain may not take effect, or may downgrade other modules, under another.
+// Each version gets a temporary copy of go.mod and go.sum; the project itself is never modified.
+func (p *Patcher) ResolveUnderGoVersions(updates []scanner.PackageUpdate, goVersions []string) []GoVersionResolution {
+	var resolutions []GoVersionResolution
+
+	for _, goVersion := range goVersions {
+		toolchain, err := ToolchainName(goVersion)
+		if err != nil {
+			resolutions = append(resolutions, GoVersionResolution{GoVersion: goVersion, Error: err})
+			continue
+		}
+
+		workspace, err := p.createWorkspace()
+		if err != nil {
+			resolutions = append(resolutions, GoVersionResolution{GoVersion: goVersion, Error: err})
+			continue
+		}
+
+		for _, upd := range updates {
+			res := p.resolveInWorkspace(workspace, toolchain, upd)
+			res.GoVersion = goVersion
+			resolutions = append(resolutions, res)
+		}
+
+		os.RemoveAll(workspace)
+	}
+
+	return resolutions
+}
+
+// createWorkspace copies go.mod and go.sum into a new temporary directory. Replace directives
+// with relative paths are made absolute so they still point into the project.
+func (p *Patcher) createWorkspace() (string, error) {
+	dir, err := os.MkdirTemp("", "grump-resolve-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary workspace: %w", err)
+	}
+
+	snap, err := p.takeSnapshot()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	goMod, err := p.absoluteReplaces(snap.GoMod)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	if snap.HasGoSum {
+		if err := os.WriteFile(filepath.Join(dir, "go.sum"), snap.GoSum, 0o644); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to write go.sum: %w", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// absoluteReplaces rewrites the relative local paths of the replace directives in goMod as
+// absolute paths under the project directory
+func (p *Patcher) absoluteReplaces(goMod []byte) ([]byte, error) {
+	f, err := modfile.Parse("go.mod", goMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	projectDir, err := filepath.Abs(p.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	changed := false
+	for _, r := range f.Replace {
+		if r.New.Version != "" || filepath.IsAbs(r.New.Path) {
+			continue
+		}
+		if err := f.AddReplace(r.Old.Path, r.Old.Version, filepath.Join(projectDir, r.New.Path), ""); err != nil {
+			return nil, fmt.Errorf("failed to rewrite replace directive of %s: %w", r.Old.Path, err)
+		}
+		changed = true
+	}
+	if !changed {
+		return goMod, nil
+	}
+	return f.Format()
+}
+
+// resolveInWorkspace runs go get for a single update in the workspace with the given toolchain
+func (p *Patcher) resolveInWorkspace(dir, toolchain string, upd scanner.PackageUpdate) GoVersionResolution {
+	res := GoVersionResolution{Package: upd.Name, Target: upd.TargetVersion}
+
+	before, err := p.listBuildList(dir, toolchain)
+	if err != nil {
+		res.Error = err
+		return res
+	}
+
+	if _, err := p.runGo(dir, toolchain, "get", fmt.Sprintf("%s@%s", upd.Name, upd.TargetVersion)); err != nil {
+		res.Error = err
+		return res
+	}
+
+	after, err := p.listBuildList(dir, toolchain)
+	if err != nil {
+		res.Error = err
+		return res
+	}
+
+	res.Resolved = after[upd.Name]
+	for path, oldVersion := range before {
+		newVersion, ok := after[path]
+		if ok && semver.Compare(newVersion, oldVersion) < 0 {
+			res.Downgrades = append(res.Downgrades, fmt.Sprintf("%s %s -> %s", path, oldVersion, newVersion))
+		}
+	}
+
+	return res
+}
+
+// listBuildList returns the selected version of every module in the build list
+func (p *Patcher) listBuildList(dir, toolchain string) (map[string]string, error) {
+	out, err := p.runGo(dir, toolchain, "list", "-m", "all")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	return versions, nil
+}
+
+// runGo runs a go command in dir pinned to the given toolchain
+func (p *Patcher) runGo(dir, toolchain string, args ...string) ([]byte, error) {
+	cmd := p.goCommand(dir, args...)
+	cmd.Env = append(cmd.Env, "GOTOOLCHAIN="+toolchain, "GOFLAGS="+p.workspaceGoFlags())
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go %s with %s failed: %w: %s", strings.Join(args, " "), toolchain, err, firstLine(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// workspaceGoFlags returns the GOFLAGS in effect for the patcher's go commands with -mod=mod
+// in place of any -mod flag. A workspace has no vendor directory, and go get must be allowed
+// to update its go.mod.
+func (p *Patcher) workspaceGoFlags() string {
+	var goFlags string
+	for _, kv := range append(environ(), p.env...) {
+		if value, ok := strings.CutPrefix(kv, "GOFLAGS="); ok {
+			goFlags = value
+		}
+	}
+
+	var flags []string
+	for _, flag := range strings.Fields(goFlags) {
+		if !strings.HasPrefix(strings.TrimLeft(flag, "-"), "mod=") {
+			flags = append(flags, flag)
+		}
+	}
+	return strings.Join(append(flags, "-mod=mod"), " ")
+}
+
+// firstLine returns the first non-empty line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,60 @@
+package patcher
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+func TestResolveUnderGoVersionsRelativeReplace(t *testing.T) {
+	proxy := newTestProxy(t)
+	proxy.add("example.com/a", "v1.0.0", "example.com/a")
+	proxy.add("example.com/a", "v1.1.0", "example.com/a")
+
+	p := newTestProject(t, `module example.com/app
+
+go 1.21
+
+require (
+	example.com/a v1.0.0
+	example.com/local v0.0.0
+)
+
+replace example.com/local => ./local
+`, map[string]string{
+		"main.go":        "package main\n\nimport (\n\t\"example.com/a\"\n\t\"example.com/local\"\n)\n\nfunc main() { println(a.Version, local.Name) }\n",
+		"local/go.mod":   "module example.com/local\n\ngo 1.21\n",
+		"local/local.go": "package local\n\nconst Name = \"local\"\n",
+	})
+	if _, err := p.RunGoTidy(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Resolve under the installed toolchain so nothing is downloaded
+	goVersion := strings.TrimPrefix(runtime.Version(), "go")
+	resolutions := p.ResolveUnderGoVersions([]scanner.PackageUpdate{
+		{Name: "example.com/a", CurrentVersion: "v1.0.0", TargetVersion: "v1.1.0"},
+	}, []string{goVersion})
+
+	if len(resolutions) != 1 {
+		t.Fatalf("got %d resolutions, want 1", len(resolutions))
+	}
+	if res := resolutions[0]; !res.Effective() || res.Resolved != "v1.1.0" {
+		t.Errorf("resolution = %+v, want example.com/a resolved to v1.1.0", res)
+	}
+}
+
+func TestWorkspaceGoFlags(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=vendor -trimpath")
+	p := &Patcher{}
+	if got := p.workspaceGoFlags(); got != "-trimpath -mod=mod" {
+		t.Errorf("workspaceGoFlags = %q, want -trimpath -mod=mod", got)
+	}
+
+	p.env = []string{"GOFLAGS=-modcacherw --mod=readonly", "GOPRIVATE=example.com"}
+	if got := p.workspaceGoFlags(); got != "-modcacherw -mod=mod" {
+		t.Errorf("workspaceGoFlags with -env = %q, want -modcacherw -mod=mod", got)
+	}
+}
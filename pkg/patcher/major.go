@@ -0,0 +1,61 @@
+package patcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ErrMajorVersionBump is returned for updates whose fix is only released under a new major
+// version module path, such as github.com/foo/bar/v2. Such updates can't be applied by bumping
+// the version in go.mod; the module's imports have to be rewritten to the new path.
+var ErrMajorVersionBump = errors.New("fix requires a new major version module path")
+
+// ErrConstraintViolation is returned when the major version constraint is enabled and a fix
+// is only available in a different major version than the one currently required
+var ErrConstraintViolation = errors.New("fix is outside the current major version")
+
+// checkSameMajor returns ErrConstraintViolation, naming both majors, if the target version is
+// in a different major version than the current one
+func checkSameMajor(pkgName, currentVersion, targetVersion string) error {
+	current, target := semver.Major(currentVersion), semver.Major(targetVersion)
+	if current == "" || target == "" || current == target {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is on %s (%s) but the fix is in %s (%s)",
+		ErrConstraintViolation, pkgName, current, currentVersion, target, targetVersion)
+}
+
+// majorVersionPath returns the module path that the target version is published under when it
+// differs from the current path because of semantic import versioning. Modules without a go.mod
+// (+incompatible versions) keep their path across major versions.
+func majorVersionPath(modulePath, targetVersion string) (string, bool) {
+	if !semver.IsValid(targetVersion) || semver.Build(targetVersion) == "+incompatible" {
+		return "", false
+	}
+	major := semver.Major(targetVersion)
+	if major == "v0" || major == "v1" {
+		return "", false
+	}
+
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok || strings.TrimLeft(pathMajor, "/.") == major {
+		return "", false
+	}
+
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		return prefix + "." + major, true
+	}
+	return prefix + "/" + major, true
+}
+
+// majorVersionError explains how to apply an update that requires a new major version path
+func majorVersionError(modulePath, currentVersion, targetVersion, newPath string) error {
+	return fmt.Errorf("%w: %s %s is fixed in %s, which is published as %s; "+
+		"require %s@%s and rewrite imports of %s to %s",
+		ErrMajorVersionBump, modulePath, currentVersion, targetVersion, newPath,
+		newPath, targetVersion, modulePath, newPath)
+}
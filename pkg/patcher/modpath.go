@@ -0,0 +1,117 @@
+package patcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/mod/modfile"
+)
+
+// moduleDownload is the subset of `go mod download -json` output used by grump
+type moduleDownload struct {
+	Path    string
+	Version string
+	GoMod   string
+	Error   string
+}
+
+// declaredPath is the result of looking up the module path a module version declares
+type declaredPath struct {
+	path string
+	err  error
+}
+
+// ReconcileModulePath returns the path under which pkgName is required in go.mod.
+// Modules that were renamed can be required under one path while declaring another in
+// their own go.mod, and advisories may use either. When pkgName is not required directly,
+// the declared path of each required module (and of pkgName at the target version) is
+// read from its downloaded go.mod to find the matching require. The second return value
+// reports whether reconciliation changed the path.
+//
+// A module version's go.mod never changes, so each lookup is made once per Patcher. In dry-run
+// mode nothing is downloaded and only go.mod files already in the module cache are read.
+func (p *Patcher) ReconcileModulePath(pkgName, version string) (string, bool) {
+	modFile, err := p.readGoMod()
+	if err != nil {
+		return pkgName, false
+	}
+
+	required := make(map[string]string, len(modFile.Require))
+	for _, req := range modFile.Require {
+		required[req.Mod.Path] = req.Mod.Version
+	}
+	if _, ok := required[pkgName]; ok {
+		return pkgName, false
+	}
+
+	// The advisory may name the old path while the fixed release declares the new one
+	if declared, err := p.declaredModulePath(pkgName, version); err == nil && declared != pkgName {
+		if _, ok := required[declared]; ok {
+			return declared, true
+		}
+	}
+
+	// Or the advisory may name the declared path of a module required under its old path
+	for _, req := range modFile.Require {
+		declared, err := p.declaredModulePath(req.Mod.Path, req.Mod.Version)
+		if err != nil {
+			continue
+		}
+		if declared == pkgName {
+			return req.Mod.Path, true
+		}
+	}
+
+	return pkgName, false
+}
+
+// declaredModulePath returns the module path declared by the go.mod of modPath at version,
+// downloading it unless it was looked up before
+func (p *Patcher) declaredModulePath(modPath, version string) (string, error) {
+	key := modPath + "@" + version
+	if cached, ok := p.declaredPaths[key]; ok {
+		return cached.path, cached.err
+	}
+	path, err := p.downloadModulePath(modPath, version)
+	p.declaredPaths[key] = declaredPath{path: path, err: err}
+	return path, err
+}
+
+// downloadModulePath downloads a module's go.mod and returns the module path it declares
+func (p *Patcher) downloadModulePath(modPath, version string) (string, error) {
+	cmd := p.goCommand(p.projectPath, "mod", "download", "-json", fmt.Sprintf("%s@%s", modPath, version))
+	if p.dryRun {
+		// A dry run stays off the network; modules that aren't cached simply aren't reconciled
+		cmd.Env = append(cmd.Environ(), "GOPROXY=off")
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	runErr := cmd.Run()
+
+	var download moduleDownload
+	if err := json.Unmarshal(stdout.Bytes(), &download); err != nil {
+		if runErr != nil {
+			return "", fmt.Errorf("failed to download %s@%s: %w", modPath, version, runErr)
+		}
+		return "", fmt.Errorf("failed to parse download info for %s@%s: %w", modPath, version, err)
+	}
+	if download.Error != "" {
+		return "", fmt.Errorf("failed to download %s@%s: %s", modPath, version, download.Error)
+	}
+	if download.GoMod == "" {
+		return "", fmt.Errorf("no go.mod downloaded for %s@%s", modPath, version)
+	}
+
+	data, err := os.ReadFile(download.GoMod)
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod of %s@%s: %w", modPath, version, err)
+	}
+
+	declared := modfile.ModulePath(data)
+	if declared == "" {
+		return "", fmt.Errorf("go.mod of %s@%s declares no module path", modPath, version)
+	}
+	return declared, nil
+}
@@ -0,0 +1,75 @@
+package patcher
+
+import "testing"
+
+func TestReconcileModulePathRenamed(t *testing.T) {
+	proxy := newTestProxy(t)
+	// example.com/old was renamed to example.com/new in v1.1.0
+	proxy.add("example.com/old", "v1.0.0", "example.com/old")
+	proxy.add("example.com/old", "v1.1.0", "example.com/new")
+	proxy.add("example.com/new", "v1.1.0", "example.com/new")
+	// example.com/legacy declares the path it was renamed to, but is still required as legacy
+	proxy.add("example.com/legacy", "v1.0.0", "example.com/current")
+
+	p := newTestProject(t, `module example.com/app
+
+go 1.21
+
+require (
+	example.com/new v1.1.0
+	example.com/legacy v1.0.0
+)
+`, nil)
+
+	tests := []struct {
+		name, version string
+		want          string
+		reconciled    bool
+	}{
+		// Required under the advisory's path
+		{"example.com/new", "v1.1.0", "example.com/new", false},
+		// The advisory names the old path, whose fixed release declares the required one
+		{"example.com/old", "v1.1.0", "example.com/new", true},
+		// The advisory names the declared path of a module required under another
+		{"example.com/current", "v1.0.1", "example.com/legacy", true},
+		// Nothing to reconcile
+		{"example.com/other", "v1.0.0", "example.com/other", false},
+	}
+	for _, tt := range tests {
+		got, reconciled := p.ReconcileModulePath(tt.name, tt.version)
+		if got != tt.want || reconciled != tt.reconciled {
+			t.Errorf("ReconcileModulePath(%q, %q) = %q, %v; want %q, %v",
+				tt.name, tt.version, got, reconciled, tt.want, tt.reconciled)
+		}
+	}
+}
+
+func TestReconcileModulePathMemoized(t *testing.T) {
+	proxy := newTestProxy(t)
+	proxy.add("example.com/legacy", "v1.0.0", "example.com/current")
+
+	p := newTestProject(t, "module example.com/app\n\ngo 1.21\n\nrequire example.com/legacy v1.0.0\n", nil)
+	if got, _ := p.ReconcileModulePath("example.com/current", "v1.0.1"); got != "example.com/legacy" {
+		t.Fatalf("ReconcileModulePath = %q, want example.com/legacy", got)
+	}
+
+	// Later lookups are answered from the cache, with the proxy gone
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	if got, _ := p.ReconcileModulePath("example.com/current", "v1.0.2"); got != "example.com/legacy" {
+		t.Errorf("ReconcileModulePath after caching = %q, want example.com/legacy", got)
+	}
+}
+
+func TestReconcileModulePathDryRunOffline(t *testing.T) {
+	proxy := newTestProxy(t)
+	proxy.add("example.com/legacy", "v1.0.0", "example.com/current")
+
+	p := newTestProject(t, "module example.com/app\n\ngo 1.21\n\nrequire example.com/legacy v1.0.0\n", nil)
+	p.SetDryRun(true)
+
+	// The module isn't in the cache, and a dry run doesn't download it
+	if got, reconciled := p.ReconcileModulePath("example.com/current", "v1.0.1"); reconciled {
+		t.Errorf("ReconcileModulePath in dry-run = %q, want no download and no reconciliation", got)
+	}
+}
@@ -1,7 +1,11 @@
 package patcher
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,23 +22,213 @@ type UpdateResult struct {
 	Update  scanner.PackageUpdate
 	Success bool
 	Error   error
+	// ModulePath is the go.mod require path that was patched when it differs from
+	// Update.Name, for modules whose declared path changed after a rename
+	ModulePath string
+	// Skipped is set when the update was intentionally not applied, see Reason
+	Skipped bool
+	Reason  string
+	// RolledBack is set when the update was applied and then reverted because it broke the build
+	RolledBack bool
+	// Mechanism is how a successful update was applied: MechanismGobump, MechanismGoGet, or
+	// MechanismAlreadySatisfied
+	Mechanism string
 }
 
+// ErrUpdateFailed wraps the error of an update that couldn't be applied, see UpdateResult.Error
+var ErrUpdateFailed = errors.New("failed to update")
+
+// ErrTidyFailed is returned when go mod tidy fails after patching
+var ErrTidyFailed = errors.New("failed to run go mod tidy")
+
+// Update mechanisms
+const (
+	MechanismGobump = "gobump"
+	MechanismGoGet  = "go get"
+	// MechanismAlreadySatisfied means go.mod already required the target version or a newer
+	// one, so nothing was changed
+	MechanismAlreadySatisfied = "already satisfied"
+)
+
+// ReasonSkippedByUser is the Reason of updates declined by the confirm function, see SetConfirm
+const ReasonSkippedByUser = "skipped by user"
+
 // Patcher handles updating Go module dependencies
 type Patcher struct {
-	projectPath string
+	projectPath  string
+	policy       Policy
+	tidyMessages []TidyMessage
+	// original is the state of go.mod and go.sum before any changes were made
+	original Snapshot
+	// dryRun records updates as successful without modifying the project
+	dryRun bool
+	// verifyBuild makes UpdateAll check that the project still builds, see BuildError
+	verifyBuild bool
+	buildErr    error
+	// getFallback retries updates that gobump can't apply with go get
+	getFallback bool
+	// skipTidy makes UpdateAll leave go mod tidy to the caller, see SetSkipTidy
+	skipTidy bool
+	// respectMajor keeps updates within the current major version, see SetRespectMajor
+	respectMajor bool
+	// verifyVersions checks fix versions against the module proxy, see SetVerifyVersions
+	verifyVersions bool
+	// publishedVersions caches the published versions of each module checked
+	publishedVersions map[string]map[string]bool
+	// declaredPaths caches the module path declared by each module@version looked up,
+	// see ReconcileModulePath
+	declaredPaths map[string]declaredPath
+	// env are extra KEY=VALUE variables for the go commands run, see SetEnv
+	env []string
+	// confirm approves each update before it is applied, see SetConfirm
+	confirm func(scanner.PackageUpdate) bool
 }
 
-// New creates a new Patcher instance
+// New creates a new Patcher instance.
+// It snapshots the project's go.mod and go.sum so the original contents remain available.
 func New(projectPath string) (*Patcher, error) {
-	return &Patcher{
-		projectPath: projectPath,
-	}, nil
+	p := &Patcher{
+		projectPath:       projectPath,
+		publishedVersions: make(map[string]map[string]bool),
+		declaredPaths:     make(map[string]declaredPath),
+	}
+
+	original, err := p.takeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	p.original = original
+
+	return p, nil
+}
+
+// SetPolicy restricts which modules UpdateAll patches
+func (p *Patcher) SetPolicy(policy Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	p.policy = policy
+	return nil
+}
+
+// SetConfirm makes UpdateAll ask confirm before applying each update that passed its checks.
+// Updates it declines are skipped with ReasonSkippedByUser.
+func (p *Patcher) SetConfirm(confirm func(scanner.PackageUpdate) bool) {
+	p.confirm = confirm
+}
+
+// SetDryRun makes UpdateAll report the updates it would make without touching go.mod or go.sum
+func (p *Patcher) SetDryRun(dryRun bool) {
+	p.dryRun = dryRun
+}
+
+// SetGetFallback makes updates that gobump fails to apply fall back to go get <pkg>@<version>,
+// which also resolves the indirect dependencies the new version needs
+func (p *Patcher) SetGetFallback(enabled bool) {
+	p.getFallback = enabled
+}
+
+// SetRespectMajor makes UpdateAll refuse updates whose fix is in a different major version
+// than the one currently required, failing them with ErrConstraintViolation
+func (p *Patcher) SetRespectMajor(respect bool) {
+	p.respectMajor = respect
+}
+
+// SetVerifyVersions makes UpdateAll check that each fix version is published, as listed by
+// go list -m -versions, before applying it. An unpublished target is replaced by the next
+// published fix version of the advisory; if there is none, the update fails with
+// ErrVersionNotPublished.
+func (p *Patcher) SetVerifyVersions(verify bool) {
+	p.verifyVersions = verify
+}
+
+// SetSkipTidy makes UpdateAll skip the final go mod tidy, for projects where tidy has
+// unwanted side effects such as removing tool dependencies. RollbackBroken still tidies.
+func (p *Patcher) SetSkipTidy(skip bool) {
+	p.skipTidy = skip
+}
+
+// SetVerifyBuild makes UpdateAll run go build ./... after patching and, if the build fails,
+// restore go.mod and go.sum to their contents before that UpdateAll call
+func (p *Patcher) SetVerifyBuild(verify bool) {
+	p.verifyBuild = verify
+}
+
+// BuildError returns the build failure found by the last UpdateAll call, if build
+// verification is enabled and the patched project no longer builds
+func (p *Patcher) BuildError() error {
+	return p.buildErr
+}
+
+// DryRun reports whether the patcher is in dry-run mode
+func (p *Patcher) DryRun() bool {
+	return p.dryRun
 }
 
 // UpdatePackage updates a single package to the specified version
 // Note: This does not run go tidy. Call RunGoTidy separately after updating packages.
+// In dry-run mode it does nothing and reports success.
 func (p *Patcher) UpdatePackage(pkgName, version string) error {
+	_, err := p.updatePackage(pkgName, version, p.getFallback)
+	return err
+}
+
+// UpdatePackageWithConstraint updates a single package like UpdatePackage, but only if the
+// target version is in the same major version as currentVersion. Otherwise it returns an error
+// wrapping ErrConstraintViolation that names both major versions.
+func (p *Patcher) UpdatePackageWithConstraint(pkgName, currentVersion, version string) error {
+	if err := checkSameMajor(pkgName, currentVersion, version); err != nil {
+		return err
+	}
+	return p.UpdatePackage(pkgName, version)
+}
+
+// updatePackage updates a single package and returns the mechanism that applied the update.
+// If gobump fails and getFallback is set, go get is tried before giving up.
+func (p *Patcher) updatePackage(pkgName, version string, getFallback bool) (string, error) {
+	// The module may have been fixed by hand or raised by an earlier bump
+	if satisfies(p.requiredVersions(), pkgName, version) {
+		slog.Info("Skipping update, go.mod already requires the version or newer", "module", pkgName, "requested", version)
+		return MechanismAlreadySatisfied, nil
+	}
+
+	if p.dryRun {
+		return MechanismGobump, nil
+	}
+
+	err := p.bumpPackage(pkgName, version)
+	if err == nil {
+		// gobump can succeed without raising the module, which go get may still manage
+		err = checkBumped(p.requiredVersions(), pkgName, version)
+	}
+	if err == nil {
+		return MechanismGobump, nil
+	}
+	if !getFallback || isAlreadyNewerVersionError(err) {
+		return "", err
+	}
+
+	slog.Info("gobump failed, retrying with go get", "module", pkgName, "error", err)
+	if getErr := p.goGet(pkgName, version); getErr != nil {
+		return "", fmt.Errorf("%w; go get fallback also failed: %v", err, getErr)
+	}
+	return MechanismGoGet, nil
+}
+
+// goGet runs go get <pkg>@<version> in the project
+func (p *Patcher) goGet(pkgName, version string) error {
+	cmd := p.goCommand(p.projectPath, "get", pkgName+"@"+version)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go get %s@%s: %w: %s", pkgName, version, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// bumpPackage updates a single package with gobump
+func (p *Patcher) bumpPackage(pkgName, version string) error {
 	// Create package map for gobump
 	pkgVersions := map[string]*types.Package{
 		pkgName: {
@@ -43,6 +237,15 @@ func (p *Patcher) UpdatePackage(pkgName, version string) error {
 		},
 	}
 
+	if err := p.doUpdate(pkgVersions); err != nil {
+		return fmt.Errorf("%w %s to %s: %w", ErrUpdateFailed, pkgName, version, err)
+	}
+
+	return nil
+}
+
+// doUpdate bumps the given packages in one gobump call, leaving go mod tidy to the caller
+func (p *Patcher) doUpdate(pkgVersions map[string]*types.Package) error {
 	// Configure update
 	config := &types.Config{
 		Modroot:         p.projectPath,
@@ -51,24 +254,63 @@ func (p *Patcher) UpdatePackage(pkgName, version string) error {
 	}
 
 	// Perform the update
-	if _, err := update.DoUpdate(pkgVersions, config); err != nil {
-		return fmt.Errorf("failed to update %s to %s: %w", pkgName, version, err)
-	}
+	return p.withEnv(func() error {
+		_, err := update.DoUpdate(pkgVersions, config)
+		return err
+	})
+}
 
-	return nil
+// HasGoSum reports whether the project has a go.sum file.
+// Patching a project without one creates it.
+func (p *Patcher) HasGoSum() bool {
+	_, err := os.Stat(filepath.Join(p.projectPath, "go.sum"))
+	return err == nil
 }
 
-// getGoVersion reads the Go version from the go.mod file
-func (p *Patcher) getGoVersion() (string, error) {
+// readGoMod reads and parses the project's go.mod file
+func (p *Patcher) readGoMod() (*modfile.File, error) {
 	goModPath := filepath.Join(p.projectPath, "go.mod")
 	data, err := os.ReadFile(goModPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read go.mod: %w", err)
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
 	}
 
 	modFile, err := modfile.Parse("go.mod", data, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse go.mod: %w", err)
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	return modFile, nil
+}
+
+// requiredVersions returns the versions go.mod requires by module path, nil if it can't be read
+func (p *Patcher) requiredVersions() map[string]string {
+	modFile, err := p.readGoMod()
+	if err != nil {
+		return nil
+	}
+	required := make(map[string]string, len(modFile.Require))
+	for _, req := range modFile.Require {
+		required[req.Mod.Path] = req.Mod.Version
+	}
+	return required
+}
+
+// satisfies reports whether the required versions already include the module at version or
+// newer
+func satisfies(required map[string]string, modulePath, version string) bool {
+	current, ok := required[modulePath]
+	if !ok || !semver.IsValid(current) || !semver.IsValid(version) {
+		return false
+	}
+	return semver.Compare(current, version) >= 0
+}
+
+// getGoVersion reads the Go version from the go.mod file
+func (p *Patcher) getGoVersion() (string, error) {
+	modFile, err := p.readGoMod()
+	if err != nil {
+		return "", err
 	}
 
 	if modFile.Go == nil {
@@ -78,51 +320,334 @@ func (p *Patcher) getGoVersion() (string, error) {
 	return modFile.Go.Version, nil
 }
 
-// RunGoTidy runs go mod tidy on the project
-func (p *Patcher) RunGoTidy() error {
+// RunGoTidy runs go mod tidy on the project and returns the classified messages
+// it printed. Messages are returned even when tidy fails.
+func (p *Patcher) RunGoTidy() ([]TidyMessage, error) {
 	// Read Go version from go.mod
 	goVersion, err := p.getGoVersion()
 	if err != nil {
 		// Log warning but don't fail - let go mod tidy use default behavior
-		fmt.Fprintf(os.Stderr, "Warning: could not read Go version from go.mod: %v\n", err)
+		slog.Warn("Could not read Go version from go.mod", "error", err)
 	}
 
-	// Configure tidy-only operation
-	config := &types.Config{
-		Modroot:         p.projectPath,
-		GoVersion:       goVersion,
-		Tidy:            true,
-		TidySkipInitial: false,
+	args := []string{"mod", "tidy"}
+	if goVersion != "" {
+		args = append(args, "-go", goVersion)
 	}
 
-	// Run tidy with empty package map
-	if _, err := update.DoUpdate(map[string]*types.Package{}, config); err != nil {
-		return fmt.Errorf("failed to run go mod tidy: %w", err)
+	// Run tidy directly rather than through gobump so that its output can be captured
+	cmd := p.goCommand(p.projectPath, args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	runErr := cmd.Run()
+
+	messages := classifyTidyOutput(output.String(), runErr != nil)
+	if runErr != nil {
+		return messages, fmt.Errorf("%w: %w", ErrTidyFailed, runErr)
 	}
 
-	return nil
+	return messages, nil
+}
+
+// TidyMessages returns the classified go mod tidy messages captured by the last UpdateAll call
+func (p *Patcher) TidyMessages() []TidyMessage {
+	return p.tidyMessages
 }
 
-// UpdateAll updates all packages in the list and runs go mod tidy at the end
+// UpdateAll updates all packages in the list and runs go mod tidy at the end.
+// Updates for the same package are coalesced first, so each package is bumped once to the
+// highest version any of its vulnerabilities requires and has a single result.
+// Errors of the run as a whole, such as a failed rollback, are only returned by UpdateAllContext.
 func (p *Patcher) UpdateAll(updates []scanner.PackageUpdate) []UpdateResult {
+	results, _ := p.UpdateAllContext(context.Background(), updates)
+	return results
+}
+
+// UpdateAllContext is UpdateAll with cancellation between packages. Once ctx is done, no
+// further packages are updated and the results of the packages already processed are
+// returned with the context error. The packages that were bumped are still tidied so go.mod
+// and go.sum stay consistent, but the build isn't verified.
+//
+// When build verification fails and go.mod and go.sum can't be restored, the results are
+// returned as applied with an error wrapping ErrRollbackFailed.
+//
+// The packages that pass the checks are bumped together with a single gobump call, which is
+// faster than one call per package and applies all bumps or none. If the combined bump fails,
+// go.mod and go.sum are restored and the packages are bumped one at a time, so each failure
+// is attributed to its package. Packages the combined bump leaves below their target are
+// bumped one at a time too, so the go get fallback still applies to them.
+func (p *Patcher) UpdateAllContext(ctx context.Context, updates []scanner.PackageUpdate) ([]UpdateResult, error) {
+	updates = scanner.CoalesceUpdates(updates)
+
+	// A failed build puts back the files as they were before this call, keeping earlier calls' bumps
+	var before Snapshot
+	if p.verifyBuild && !p.dryRun {
+		var err error
+		if before, err = p.takeSnapshot(); err != nil {
+			return nil, err
+		}
+	}
+
 	results := make([]UpdateResult, 0, len(updates))
+	var pending []pendingUpdate
+	var ctxErr error
+	required := p.requiredVersions()
+
+	// Check every package before anything is changed
+	for _, upd := range updates {
+		if err := ctx.Err(); err != nil {
+			ctxErr = fmt.Errorf("patching stopped after %d of %d packages: %w", len(results)+len(pending), len(updates), err)
+			slog.Warn("Patching stopped before all packages were updated", "error", ctxErr)
+			break
+		}
+
+		if result, ok := p.checkUpdate(&upd); !ok {
+			results = append(results, result)
+			continue
+		}
+
+		// Advisories may name a renamed module by a path other than its go.mod require path
+		modulePath, reconciled := p.ReconcileModulePath(upd.Name, upd.TargetVersion)
+		if reconciled {
+			slog.Info("Reconciled module to required path", "module", upd.Name, "path", modulePath)
+		}
+
+		// A fix released under a new major version path can't be applied by bumping go.mod
+		if newPath, ok := majorVersionPath(modulePath, upd.TargetVersion); ok {
+			err := majorVersionError(modulePath, upd.CurrentVersion, upd.TargetVersion, newPath)
+			slog.Warn("Not updating module", "module", upd.Name, "error", err)
+			result := UpdateResult{Update: upd, Error: err}
+			if reconciled {
+				result.ModulePath = modulePath
+			}
+			results = append(results, result)
+			continue
+		}
+
+		pu := pendingUpdate{update: upd, modulePath: modulePath, reconciled: reconciled}
+
+		// A module already at the fix version, for example fixed by hand, needs no bump
+		if satisfies(required, modulePath, upd.TargetVersion) {
+			slog.Info("Skipping update, go.mod already requires the version or newer",
+				"module", upd.Name, "version", required[modulePath], "requested", upd.TargetVersion)
+			results = append(results, pu.result(true, nil, MechanismAlreadySatisfied))
+			continue
+		}
+
+		// Every check passed, but the user may still decline the update
+		if p.confirm != nil && !p.confirm(upd) {
+			result := UpdateResult{Update: upd, Skipped: true, Reason: ReasonSkippedByUser}
+			if reconciled {
+				result.ModulePath = modulePath
+			}
+			results = append(results, result)
+			continue
+		}
+
+		pending = append(pending, pu)
+	}
+
+	// Bump everything at once; fall back to one package at a time to find the ones that fail.
+	// Packages the combined bump leaves behind are retried on their own, with the go get fallback.
+	if len(pending) > 1 && !p.dryRun && ctxErr == nil {
+		batched, remaining, err := p.updateBatch(pending)
+		if err != nil {
+			slog.Info("Combined update failed, updating packages one at a time", "error", err)
+		} else {
+			results = append(results, batched...)
+			pending = remaining
+		}
+	}
+	serial, err := p.updateSerially(ctx, pending)
+	results = append(results, serial...)
+	if err != nil && ctxErr == nil {
+		ctxErr = fmt.Errorf("patching stopped after %d of %d packages: %w", len(results), len(updates), err)
+		slog.Warn("Patching stopped before all packages were updated", "error", ctxErr)
+	}
+
+	// Nothing was changed, so there is nothing to tidy
+	if p.dryRun {
+		return results, ctxErr
+	}
+
+	// Run go mod tidy after all updates, even if some failed
+	if p.skipTidy {
+		slog.Info("Skipping go mod tidy")
+	} else {
+		messages, err := p.RunGoTidy()
+		p.tidyMessages = messages
+		if err != nil {
+			// Log the error but don't fail the entire operation
+			slog.Warn("go mod tidy failed", "error", err)
+		} else {
+			// Tidy drops bumps of modules nothing imports, which would leave them vulnerable
+			results = p.checkTidyReverted(results)
+		}
+	}
+
+	// A security bump can pull in an incompatible API change; if one did, put the project back.
+	// An interrupted run is out of time, so the build is left for the caller to check.
+	if p.verifyBuild && ctxErr == nil {
+		p.buildErr = p.VerifyBuild()
+		if p.buildErr != nil {
+			if err := p.rollbackTo(before); err != nil {
+				// The patched files are still in place, so the results stay as applied
+				rollbackErr := fmt.Errorf("%w after build failure: %w", ErrRollbackFailed, err)
+				slog.Error("Build failed after patching and go.mod and go.sum could not be restored", "error", err)
+				return results, rollbackErr
+			}
+			slog.Warn("Build failed after patching, restored go.mod and go.sum")
+			p.tidyMessages = nil
+			results = markAllRolledBack(results, p.buildErr)
+		}
+	}
+
+	return results, ctxErr
+}
+
+// pendingUpdate is an update that passed the checks in UpdateAllContext and is to be applied
+type pendingUpdate struct {
+	update     scanner.PackageUpdate
+	modulePath string
+	reconciled bool
+}
+
+// result returns the UpdateResult of applying the update
+func (pu pendingUpdate) result(success bool, err error, mechanism string) UpdateResult {
+	result := UpdateResult{
+		Update:    pu.update,
+		Success:   success,
+		Error:     err,
+		Mechanism: mechanism,
+	}
+	if pu.reconciled {
+		result.ModulePath = pu.modulePath
+	}
+	return result
+}
+
+// checkUpdate applies the replace, policy, published version, and major version checks to an
+// update. If the update must not be applied, it returns the skipped or failed result and false.
+// Verifying the published version may change the update's target.
+func (p *Patcher) checkUpdate(upd *scanner.PackageUpdate) (UpdateResult, bool) {
+	// A replace directive decides the module's version, so bumping the require line would be a no-op
+	if upd.Replace != "" {
+		slog.Warn("Skipping replaced module; update the replace directive instead",
+			"module", upd.Name, "replace", upd.Replace, "vulnerability", upd.VulnID)
+		return UpdateResult{
+			Update:  *upd,
+			Skipped: true,
+			Reason:  upd.Replace + "; update the replace directive instead",
+		}, false
+	}
+
+	// Updates outside the patch policy are reported but left untouched
+	if allowed, reason := p.policy.Allows(*upd); !allowed {
+		return UpdateResult{
+			Update:  *upd,
+			Skipped: true,
+			Reason:  reason,
+		}, false
+	}
+
+	// Grype can suggest a fix version that was never published
+	if p.verifyVersions {
+		target, err := p.publishedTarget(*upd)
+		if err != nil {
+			slog.Warn("Not updating module", "module", upd.Name, "error", err)
+			return UpdateResult{Update: *upd, Error: err}, false
+		}
+		upd.TargetVersion = target
+	}
+
+	// A fix in another major version may be deliberately out of reach
+	if p.respectMajor {
+		if err := checkSameMajor(upd.Name, upd.CurrentVersion, upd.TargetVersion); err != nil {
+			slog.Warn("Not updating module", "module", upd.Name, "error", err)
+			return UpdateResult{Update: *upd, Error: err}, false
+		}
+	}
+
+	return UpdateResult{}, true
+}
+
+// updateBatch bumps all pending packages with a single gobump call and then checks the version
+// go.mod requires for each. The packages that reached their target are returned as results, the
+// ones left behind as remaining, to be updated one at a time. If the call fails, go.mod and
+// go.sum are restored and the error is returned.
+func (p *Patcher) updateBatch(pending []pendingUpdate) (results []UpdateResult, remaining []pendingUpdate, err error) {
+	before, err := p.takeSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgVersions := make(map[string]*types.Package, len(pending))
+	for _, pu := range pending {
+		pkgVersions[pu.modulePath] = &types.Package{
+			Name:    pu.modulePath,
+			Version: pu.update.TargetVersion,
+		}
+	}
+	if err := p.doUpdate(pkgVersions); err != nil {
+		if restoreErr := p.restore(before); restoreErr != nil {
+			return nil, nil, fmt.Errorf("%w; restoring go.mod and go.sum also failed: %v", err, restoreErr)
+		}
+		return nil, nil, err
+	}
+
+	required := p.requiredVersions()
+	for _, pu := range pending {
+		if err := checkBumped(required, pu.modulePath, pu.update.TargetVersion); err != nil {
+			slog.Info("Combined update left a package behind, updating it on its own", "module", pu.update.Name, "error", err)
+			remaining = append(remaining, pu)
+			continue
+		}
+		results = append(results, pu.result(true, nil, MechanismGobump))
+	}
+	return results, remaining, nil
+}
+
+// checkBumped returns an error unless go.mod requires modulePath at version or newer
+func checkBumped(required map[string]string, modulePath, version string) error {
+	current, ok := required[modulePath]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w %s to %s: go.mod does not require it after the update",
+			ErrUpdateFailed, modulePath, version)
+	case semver.Compare(current, version) < 0:
+		return fmt.Errorf("%w %s to %s: go.mod requires %s after the update",
+			ErrUpdateFailed, modulePath, version, current)
+	}
+	return nil
+}
+
+// updateSerially applies the pending updates one package at a time, stopping when ctx is done
+func (p *Patcher) updateSerially(ctx context.Context, pending []pendingUpdate) ([]UpdateResult, error) {
+	results := make([]UpdateResult, 0, len(pending))
 	// Track which packages have been updated and to what version
 	appliedVersions := make(map[string]string)
 
-	// Update all packages first
-	for _, upd := range updates {
+	for _, pu := range pending {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		upd := pu.update
+
 		// Check if package has already been updated in this session
 		if appliedVersion, exists := appliedVersions[upd.Name]; exists {
 			// Compare versions to see if we should skip
 			if shouldSkipUpdate(appliedVersion, upd.TargetVersion) {
 				// Skip this update - the package is already at a newer or same version
-				fmt.Fprintf(os.Stderr, "Skipping %s: already at version %s (requested %s)\n",
-					upd.Name, appliedVersion, upd.TargetVersion)
+				slog.Info("Skipping update, already applied",
+					"module", upd.Name, "version", appliedVersion, "requested", upd.TargetVersion)
 				continue
 			}
 		}
 
-		err := p.UpdatePackage(upd.Name, upd.TargetVersion)
+		// go get is opt-in for direct and indirect dependencies alike, so an update is applied
+		// the same way whether or not the combined bump failed
+		mechanism, err := p.updatePackage(pu.modulePath, upd.TargetVersion, p.getFallback)
 
 		// Check if the error is because the package is already at a newer version
 		// In this case, treat it as success since the vulnerability is already resolved
@@ -130,29 +655,17 @@ func (p *Patcher) UpdateAll(updates []scanner.PackageUpdate) []UpdateResult {
 		if err != nil && isAlreadyNewerVersionError(err) {
 			success = true
 			// Still record the error for informational purposes, but mark as success
-			fmt.Fprintf(os.Stderr, "Skipping %s: already at or newer version (requested %s)\n",
-				upd.Name, upd.TargetVersion)
+			slog.Info("Skipping update, already at or newer version",
+				"module", upd.Name, "requested", upd.TargetVersion)
 		}
-
-		results = append(results, UpdateResult{
-			Update:  upd,
-			Success: success,
-			Error:   err,
-		})
+		results = append(results, pu.result(success, err, mechanism))
 
 		// Track the applied version if successful
 		if success {
 			appliedVersions[upd.Name] = upd.TargetVersion
 		}
 	}
-
-	// Run go mod tidy after all updates, even if some failed
-	if err := p.RunGoTidy(); err != nil {
-		// Log the error but don't fail the entire operation
-		fmt.Fprintf(os.Stderr, "Warning: go mod tidy failed: %v\n", err)
-	}
-
-	return results
+	return results, nil
 }
 
 // shouldSkipUpdate compares two versions and returns true if the applied version
@@ -0,0 +1,98 @@
+package patcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/zip"
+)
+
+// testProxy is a file-based module proxy, so tests can run go commands without the network
+type testProxy struct {
+	t   *testing.T
+	dir string
+}
+
+// newTestProxy points the go command at an empty file-based module proxy and a private module
+// cache for the rest of the test
+func newTestProxy(t *testing.T) *testProxy {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(dir))
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GONOSUMDB", "")
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GOTOOLCHAIN", "local")
+	t.Setenv("GOWORK", "off")
+	return &testProxy{t: t, dir: dir}
+}
+
+// add publishes a module version whose go.mod declares declaredPath. A package is included
+// so the module can be imported.
+func (tp *testProxy) add(modPath, version, declaredPath string) {
+	tp.t.Helper()
+	tp.addFiles(modPath, version, map[string]string{
+		"go.mod": "module " + declaredPath + "\n\ngo 1.21\n",
+		"lib.go": "package " + filepath.Base(declaredPath) + "\n\nconst Version = \"" + version + "\"\n",
+	})
+}
+
+// addFiles publishes a module version made of the given files, which must include its go.mod
+func (tp *testProxy) addFiles(modPath, version string, files map[string]string) {
+	tp.t.Helper()
+	src := tp.t.TempDir()
+	for name, content := range files {
+		writeFile(tp.t, filepath.Join(src, name), content)
+	}
+
+	modDir, err := module.EscapePath(modPath)
+	if err != nil {
+		tp.t.Fatal(err)
+	}
+	versionDir := filepath.Join(tp.dir, modDir, "@v")
+	writeFile(tp.t, filepath.Join(versionDir, version+".mod"), files["go.mod"])
+	writeFile(tp.t, filepath.Join(versionDir, version+".info"), `{"Version":"`+version+`","Time":"2024-01-01T00:00:00Z"}`)
+
+	f, err := os.Create(filepath.Join(versionDir, version+".zip"))
+	if err != nil {
+		tp.t.Fatal(err)
+	}
+	defer f.Close()
+	if err := zip.CreateFromDir(f, module.Version{Path: modPath, Version: version}, src); err != nil {
+		tp.t.Fatal(err)
+	}
+
+	list, _ := os.ReadFile(filepath.Join(versionDir, "list"))
+	writeFile(tp.t, filepath.Join(versionDir, "list"), string(list)+version+"\n")
+}
+
+// writeFile writes content to path, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestProject writes a project with the given go.mod and files to a temporary directory
+// and returns a Patcher for it
+func newTestProject(t *testing.T, goMod string, files map[string]string) *Patcher {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), goMod)
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	p, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
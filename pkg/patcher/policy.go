@@ -0,0 +1,80 @@
+package patcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/scanner"
+	"golang.org/x/mod/module"
+)
+
+// Policy restricts which modules UpdateAll is allowed to patch automatically.
+// Updates rejected by the policy are still reported, marked as skipped with a reason.
+type Policy struct {
+	// AllowedPrefixes limits patching to modules under these path prefixes.
+	// An empty list allows all modules.
+	AllowedPrefixes []string
+	// MinConfidence skips updates whose match confidence is below this level (low, medium, high).
+	// Empty allows all confidence levels.
+	MinConfidence string
+	// MaxSeverity leaves updates whose effective severity is above this one for manual review
+	// (negligible, low, medium, high, critical). Empty allows all severities.
+	MaxSeverity string
+	// OnlyDirect leaves updates of indirect dependencies to be fixed upstream
+	OnlyDirect bool
+}
+
+// Validate checks that the policy is well-formed
+func (pol Policy) Validate() error {
+	for _, prefix := range pol.AllowedPrefixes {
+		trimmed := strings.TrimSuffix(prefix, "/")
+		if trimmed == "" {
+			return fmt.Errorf("invalid patch prefix %q: must not be empty", prefix)
+		}
+		if err := module.CheckImportPath(trimmed); err != nil {
+			return fmt.Errorf("invalid patch prefix %q: %w", prefix, err)
+		}
+	}
+	if pol.MinConfidence != "" && scanner.ConfidenceRank(pol.MinConfidence) == 0 {
+		return fmt.Errorf("invalid minimum confidence %q: must be low, medium, or high", pol.MinConfidence)
+	}
+	if pol.MaxSeverity != "" && scanner.SeverityRank(pol.MaxSeverity) == 0 {
+		return fmt.Errorf("invalid maximum severity %q: must be negligible, low, medium, high, or critical", pol.MaxSeverity)
+	}
+	return nil
+}
+
+// Allows reports whether the update may be patched, and the reason when it may not
+func (pol Policy) Allows(upd scanner.PackageUpdate) (bool, string) {
+	if pol.MinConfidence != "" && scanner.ConfidenceRank(upd.Confidence) < scanner.ConfidenceRank(pol.MinConfidence) {
+		return false, fmt.Sprintf("match confidence %s is below minimum %s", upd.Confidence, pol.MinConfidence)
+	}
+
+	if pol.OnlyDirect && !upd.Direct {
+		return false, "upstream fix needed: indirect dependency"
+	}
+
+	// Escalated and overridden severities count, as they do for the minimum severity
+	severity := upd.SeverityForGating()
+	if pol.MaxSeverity != "" && scanner.SeverityRank(severity) > scanner.SeverityRank(pol.MaxSeverity) {
+		return false, fmt.Sprintf("needs manual review: severity %s is above maximum %s", severity, pol.MaxSeverity)
+	}
+
+	if len(pol.AllowedPrefixes) == 0 {
+		return true, ""
+	}
+
+	for _, prefix := range pol.AllowedPrefixes {
+		if hasPathPrefix(upd.Name, prefix) {
+			return true, ""
+		}
+	}
+
+	return false, fmt.Sprintf("deferred by policy: not under an allowed prefix (%s)", strings.Join(pol.AllowedPrefixes, ", "))
+}
+
+// hasPathPrefix reports whether path is prefix itself or lies under it on a path element boundary
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,90 @@
+package patcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		update scanner.PackageUpdate
+		allow  bool
+		reason string
+	}{
+		{
+			name:   "empty policy",
+			update: scanner.PackageUpdate{Name: "example.com/a", Severity: "Critical"},
+			allow:  true,
+		},
+		{
+			name:   "under an allowed prefix",
+			policy: Policy{AllowedPrefixes: []string{"example.com/"}},
+			update: scanner.PackageUpdate{Name: "example.com/a"},
+			allow:  true,
+		},
+		{
+			name:   "prefix matches on path elements only",
+			policy: Policy{AllowedPrefixes: []string{"example.com/a"}},
+			update: scanner.PackageUpdate{Name: "example.com/ab"},
+			reason: "deferred by policy",
+		},
+		{
+			name:   "confidence below minimum",
+			policy: Policy{MinConfidence: scanner.ConfidenceMedium},
+			update: scanner.PackageUpdate{Name: "example.com/a", Confidence: scanner.ConfidenceLow},
+			reason: "match confidence low is below minimum medium",
+		},
+		{
+			name:   "indirect dependency",
+			policy: Policy{OnlyDirect: true},
+			update: scanner.PackageUpdate{Name: "example.com/a"},
+			reason: "upstream fix needed",
+		},
+		{
+			name:   "severity at the cap",
+			policy: Policy{MaxSeverity: "high"},
+			update: scanner.PackageUpdate{Name: "example.com/a", Severity: "High", EffectiveSeverity: "High"},
+			allow:  true,
+		},
+		{
+			name:   "escalated above the cap",
+			policy: Policy{MaxSeverity: "medium"},
+			update: scanner.PackageUpdate{Name: "example.com/a", Severity: "Low", EffectiveSeverity: "Critical"},
+			reason: "needs manual review: severity Critical is above maximum medium",
+		},
+		{
+			name:   "overridden below the cap",
+			policy: Policy{MaxSeverity: "medium"},
+			update: scanner.PackageUpdate{Name: "example.com/a", Severity: "Critical", EffectiveSeverity: "Low"},
+			allow:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			allow, reason := tt.policy.Allows(tt.update)
+			if allow != tt.allow || !strings.HasPrefix(reason, tt.reason) {
+				t.Errorf("Allows = %v, %q; want %v, %q", allow, reason, tt.allow, tt.reason)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	for _, policy := range []Policy{
+		{AllowedPrefixes: []string{"/"}},
+		{AllowedPrefixes: []string{"example.com/a b"}},
+		{MinConfidence: "certain"},
+		{MaxSeverity: "severe"},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", policy)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package patcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOutOfSync is returned by CheckSync when go.sum is missing entries go.mod needs
+var ErrOutOfSync = errors.New("go.mod and go.sum are out of sync")
+
+// outOfSyncMessages identify go command failures caused by an inconsistent go.mod and go.sum,
+// as opposed to network or toolchain problems
+var outOfSyncMessages = []string{
+	"missing go.sum entry",
+	"updates to go.mod needed",
+	"go.sum: ",
+}
+
+// CheckSync checks that go.mod and go.sum in dir are consistent by listing the build list
+// without allowing go to modify either file. It returns an error wrapping ErrOutOfSync when
+// they aren't; other failures, for example when the module proxy is unreachable, are
+// returned as is. env are extra KEY=VALUE variables for the go command, see Patcher.SetEnv.
+func CheckSync(dir string, env ...string) error {
+	p := &Patcher{env: env}
+	cmd := p.goCommand(dir, "list", "-mod=readonly", "-m", "all")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		for _, msg := range outOfSyncMessages {
+			if strings.Contains(output, msg) {
+				return fmt.Errorf("%w: %s", ErrOutOfSync, firstLine(output))
+			}
+		}
+		return fmt.Errorf("go list -m all: %w: %s", err, firstLine(output))
+	}
+	return nil
+}
+
+// DownloadModules runs go mod download in dir, which downloads the build list and adds the
+// go.sum entries that are missing
+func DownloadModules(dir string, env ...string) error {
+	p := &Patcher{env: env}
+	cmd := p.goCommand(dir, "mod", "download")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go mod download: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
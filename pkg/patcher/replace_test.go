@@ -0,0 +1,54 @@
+package patcher
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+func TestUpdateAllSkipsReplacedModules(t *testing.T) {
+	goMod := `module example.com/app
+
+go 1.21
+
+require (
+	example.com/local v1.0.0
+	example.com/forked v1.0.0
+)
+
+replace example.com/local => ../local
+
+replace example.com/forked => example.com/fork v1.5.0
+`
+	p := newTestProject(t, goMod, nil)
+	p.SetSkipTidy(true)
+
+	results := p.UpdateAll([]scanner.PackageUpdate{
+		{Name: "example.com/local", CurrentVersion: "v1.0.0", TargetVersion: "v1.0.1", VulnID: "GHSA-local",
+			Replace: "replaced by local path ../local"},
+		{Name: "example.com/fork", CurrentVersion: "v1.5.0", TargetVersion: "v1.5.1", VulnID: "GHSA-fork",
+			Replace: "replaced by example.com/fork v1.5.0"},
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		if !result.Skipped || result.Success || result.Error != nil {
+			t.Errorf("%s: skipped = %v, success = %v, error = %v; want skipped only",
+				result.Update.Name, result.Skipped, result.Success, result.Error)
+		}
+		if want := result.Update.Replace + "; update the replace directive instead"; result.Reason != want {
+			t.Errorf("%s: reason = %q, want %q", result.Update.Name, result.Reason, want)
+		}
+	}
+
+	current, err := p.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(current.GoMod, p.Original().GoMod) {
+		t.Errorf("go.mod changed:\n%s", current.GoMod)
+	}
+}
@@ -0,0 +1,71 @@
+package patcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ErrTidyReverted is the error of an update that go mod tidy undid. Tidy drops the require
+// line of a module that no package of the build imports, after which the module falls back
+// to the version the rest of the dependency graph asks for.
+var ErrTidyReverted = errors.New("go mod tidy reverted the update")
+
+// effectiveVersions returns the selected version of each module in the build list, as
+// reported by go list -m all
+func (p *Patcher) effectiveVersions() (map[string]string, error) {
+	cmd := p.goCommand(p.projectPath, "list", "-m", "-f", "{{.Path}} {{.Version}}", "all")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list -m all: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	versions := make(map[string]string)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if path, version, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			versions[path] = version
+		}
+	}
+	return versions, nil
+}
+
+// checkTidyReverted marks the successful results whose module go mod tidy set back below the
+// target version as failed with ErrTidyReverted. A module that left the build list entirely
+// is no longer a dependency, so its update still counts as a fix.
+func (p *Patcher) checkTidyReverted(results []UpdateResult) []UpdateResult {
+	versions, err := p.effectiveVersions()
+	if err != nil {
+		slog.Warn("Could not verify module versions after go mod tidy", "error", err)
+		return results
+	}
+
+	for i := range results {
+		result := &results[i]
+		if !result.Success {
+			continue
+		}
+		modulePath := result.Update.Name
+		if result.ModulePath != "" {
+			modulePath = result.ModulePath
+		}
+		effective, ok := versions[modulePath]
+		if !ok || !semver.IsValid(effective) || !semver.IsValid(result.Update.TargetVersion) ||
+			semver.Compare(effective, result.Update.TargetVersion) >= 0 {
+			continue
+		}
+
+		slog.Warn("go mod tidy reverted update", "module", modulePath,
+			"version", effective, "requested", result.Update.TargetVersion)
+		result.Success = false
+		result.Error = fmt.Errorf("%w: %s is at %s instead of %s because no package in the build imports it; "+
+			"keep the fix with a tool directive or a blank import of one of its packages, which tidy won't remove",
+			ErrTidyReverted, modulePath, effective, result.Update.TargetVersion)
+	}
+	return results
+}
@@ -0,0 +1,232 @@
+package patcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ErrBuildFailed is returned by VerifyBuild when the patched project doesn't compile
+var ErrBuildFailed = errors.New("go build failed")
+
+// ErrRollbackFailed is returned by UpdateAllContext when the build broke and go.mod and go.sum
+// could not be restored, leaving the project patched
+var ErrRollbackFailed = errors.New("failed to roll back")
+
+// VerifyBuild runs go build ./... in the project and returns an error with the compiler output if it fails
+func (p *Patcher) VerifyBuild() error {
+	cmd := p.goCommand(p.projectPath, "build", "./...")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %w\n%s", ErrBuildFailed, err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// restore writes a snapshot back to go.mod and go.sum
+func (p *Patcher) restore(snap Snapshot) error {
+	if err := os.WriteFile(filepath.Join(p.projectPath, "go.mod"), snap.GoMod, 0o644); err != nil {
+		return fmt.Errorf("failed to restore go.mod: %w", err)
+	}
+
+	goSumPath := filepath.Join(p.projectPath, "go.sum")
+	if !snap.HasGoSum {
+		if err := os.Remove(goSumPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove go.sum: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(goSumPath, snap.GoSum, 0o644); err != nil {
+		return fmt.Errorf("failed to restore go.sum: %w", err)
+	}
+	return nil
+}
+
+// Rollback restores go.mod and go.sum to their contents when the Patcher was created.
+// Only files that changed are rewritten, so a run that touched go.mod but not go.sum leaves
+// go.sum alone, and a go.sum created by patching is removed.
+func (p *Patcher) Rollback() error {
+	return p.rollbackTo(p.original)
+}
+
+// rollbackTo restores go.mod and go.sum to a snapshot, rewriting only the files that changed
+func (p *Patcher) rollbackTo(snap Snapshot) error {
+	current, err := p.takeSnapshot()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current.GoMod, snap.GoMod) {
+		if err := os.WriteFile(filepath.Join(p.projectPath, "go.mod"), snap.GoMod, 0o644); err != nil {
+			return fmt.Errorf("failed to restore go.mod: %w", err)
+		}
+	}
+
+	goSumPath := filepath.Join(p.projectPath, "go.sum")
+	switch {
+	case !snap.HasGoSum && current.HasGoSum:
+		if err := os.Remove(goSumPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove go.sum: %w", err)
+		}
+	case snap.HasGoSum && (!current.HasGoSum || !bytes.Equal(current.GoSum, snap.GoSum)):
+		if err := os.WriteFile(goSumPath, snap.GoSum, 0o644); err != nil {
+			return fmt.Errorf("failed to restore go.sum: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RollbackPackage restores a single module's require line to its pre-patch version and re-runs
+// go mod tidy, leaving every other change intact. A module that was not required before
+// patching is dropped from go.mod.
+func (p *Patcher) RollbackPackage(name string) error {
+	original, err := modfile.Parse("go.mod", p.original.GoMod, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse original go.mod: %w", err)
+	}
+
+	current, err := p.readGoMod()
+	if err != nil {
+		return err
+	}
+
+	originalVersion := ""
+	for _, req := range original.Require {
+		if req.Mod.Path == name {
+			originalVersion = req.Mod.Version
+			break
+		}
+	}
+
+	if originalVersion == "" {
+		err = current.DropRequire(name)
+	} else {
+		err = current.AddRequire(name, originalVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back %s: %w", name, err)
+	}
+
+	current.Cleanup()
+	data, err := current.Format()
+	if err != nil {
+		return fmt.Errorf("failed to format go.mod: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.projectPath, "go.mod"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write go.mod: %w", err)
+	}
+
+	if _, err := p.RunGoTidy(); err != nil {
+		return fmt.Errorf("failed to tidy after rolling back %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// RollbackBroken verifies the build after patching and, if it fails, rolls back the updates
+// that broke it while keeping the rest. Each updated package is rolled back on its own until the
+// build passes; if no single rollback fixes the build, every updated package is rolled back.
+// Rolled back results are marked as unsuccessful with RolledBack set and the build error recorded.
+// If the build fails even with everything rolled back, the updates are kept and the build
+// error is left for BuildError.
+func (p *Patcher) RollbackBroken(results []UpdateResult) ([]UpdateResult, error) {
+	buildErr := p.VerifyBuild()
+	if buildErr == nil {
+		return results, nil
+	}
+
+	var updated []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		name := result.Update.Name
+		if result.ModulePath != "" {
+			name = result.ModulePath
+		}
+		if result.Success && !seen[name] {
+			seen[name] = true
+			updated = append(updated, name)
+		}
+	}
+
+	for _, name := range updated {
+		snap, err := p.takeSnapshot()
+		if err != nil {
+			return results, err
+		}
+
+		slog.Info("Build failed, trying rollback", "module", name)
+		if err := p.RollbackPackage(name); err != nil {
+			return results, err
+		}
+
+		if p.VerifyBuild() == nil {
+			p.buildErr = nil
+			return markRolledBack(results, map[string]bool{name: true}, buildErr), nil
+		}
+
+		if err := p.restore(snap); err != nil {
+			return results, err
+		}
+	}
+
+	// No single package is to blame; roll everything back
+	patched, err := p.takeSnapshot()
+	if err != nil {
+		return results, err
+	}
+	for _, name := range updated {
+		if err := p.RollbackPackage(name); err != nil {
+			return results, err
+		}
+	}
+
+	// A project that didn't build before patching doesn't build without the fixes either
+	if err := p.VerifyBuild(); err != nil {
+		slog.Warn("Build still fails with every update rolled back, keeping the updates", "error", err)
+		if err := p.restore(patched); err != nil {
+			return results, err
+		}
+		p.buildErr = buildErr
+		return results, nil
+	}
+
+	p.buildErr = nil
+	return markRolledBack(results, seen, buildErr), nil
+}
+
+// markAllRolledBack marks every successful result as rolled back
+func markAllRolledBack(results []UpdateResult, buildErr error) []UpdateResult {
+	for i := range results {
+		if results[i].Success {
+			results[i].Success = false
+			results[i].RolledBack = true
+			results[i].Error = buildErr
+		}
+	}
+	return results
+}
+
+// markRolledBack marks successful results for the named modules as rolled back
+func markRolledBack(results []UpdateResult, names map[string]bool, buildErr error) []UpdateResult {
+	for i := range results {
+		name := results[i].Update.Name
+		if results[i].ModulePath != "" {
+			name = results[i].ModulePath
+		}
+		if results[i].Success && names[name] {
+			results[i].Success = false
+			results[i].RolledBack = true
+			results[i].Error = buildErr
+		}
+	}
+	return results
+}
@@ -0,0 +1,210 @@
+package patcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// newUnpatchedProject returns a Patcher for a project that uses example.com/a and
+// example.com/b at v1.0.0 and builds. Bumping example.com/b to v1.1.0 removes a function the
+// project calls, so the project no longer builds; example.com/a v1.1.0 is harmless.
+func newUnpatchedProject(t *testing.T) *Patcher {
+	t.Helper()
+	proxy := newTestProxy(t)
+	proxy.add("example.com/a", "v1.0.0", "example.com/a")
+	proxy.add("example.com/a", "v1.1.0", "example.com/a")
+	proxy.addFiles("example.com/b", "v1.0.0", map[string]string{
+		"go.mod": "module example.com/b\n\ngo 1.21\n",
+		"b.go":   "package b\n\nfunc Hello() string { return \"hello\" }\n",
+	})
+	proxy.addFiles("example.com/b", "v1.1.0", map[string]string{
+		"go.mod": "module example.com/b\n\ngo 1.21\n",
+		"b.go":   "package b\n\nfunc Greet() string { return \"hello\" }\n",
+	})
+
+	setup := newTestProject(t, "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n)\n",
+		map[string]string{
+			"main.go": "package main\n\nimport (\n\t\"example.com/a\"\n\t\"example.com/b\"\n)\n\nfunc main() { println(a.Version, b.Hello()) }\n",
+		})
+	if _, err := setup.RunGoTidy(); err != nil {
+		t.Fatal(err)
+	}
+	if err := setup.VerifyBuild(); err != nil {
+		t.Fatalf("project doesn't build before patching: %v", err)
+	}
+
+	p, err := New(setup.projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// newBreakingProject returns the project of newUnpatchedProject with both modules bumped to
+// v1.1.0 after the Patcher was created, so it no longer builds
+func newBreakingProject(t *testing.T) *Patcher {
+	t.Helper()
+	p := newUnpatchedProject(t)
+	writeFile(t, filepath.Join(p.projectPath, "go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/a v1.1.0\n\texample.com/b v1.1.0\n)\n")
+	if _, err := p.RunGoTidy(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRollbackPackage(t *testing.T) {
+	p := newBreakingProject(t)
+	if err := p.VerifyBuild(); !errors.Is(err, ErrBuildFailed) {
+		t.Fatalf("VerifyBuild = %v, want ErrBuildFailed", err)
+	}
+
+	if err := p.RollbackPackage("example.com/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	required := p.requiredVersions()
+	if required["example.com/a"] != "v1.1.0" || required["example.com/b"] != "v1.0.0" {
+		t.Errorf("required = %v, want example.com/a v1.1.0 kept and example.com/b v1.0.0 restored", required)
+	}
+	if err := p.VerifyBuild(); err != nil {
+		t.Errorf("VerifyBuild after rollback = %v", err)
+	}
+}
+
+func TestRollbackBrokenKeepsOtherUpdates(t *testing.T) {
+	p := newBreakingProject(t)
+	results := []UpdateResult{
+		{Update: scanner.PackageUpdate{Name: "example.com/a", TargetVersion: "v1.1.0"}, Success: true},
+		{Update: scanner.PackageUpdate{Name: "example.com/b", TargetVersion: "v1.1.0"}, Success: true},
+	}
+
+	results, err := p.RollbackBroken(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept, rolledBack := results[0], results[1]
+	if !kept.Success || kept.RolledBack {
+		t.Errorf("example.com/a: success = %v, rolled back = %v; want kept", kept.Success, kept.RolledBack)
+	}
+	if rolledBack.Success || !rolledBack.RolledBack || !errors.Is(rolledBack.Error, ErrBuildFailed) {
+		t.Errorf("example.com/b: success = %v, rolled back = %v, error = %v; want rolled back with ErrBuildFailed",
+			rolledBack.Success, rolledBack.RolledBack, rolledBack.Error)
+	}
+	if required := p.requiredVersions(); required["example.com/a"] != "v1.1.0" || required["example.com/b"] != "v1.0.0" {
+		t.Errorf("required = %v, want example.com/a v1.1.0 and example.com/b v1.0.0", required)
+	}
+	if p.BuildError() != nil {
+		t.Errorf("BuildError = %v, want nil once the build passes", p.BuildError())
+	}
+}
+
+func TestVerifyBuildRollsBack(t *testing.T) {
+	p := newUnpatchedProject(t)
+	p.SetVerifyBuild(true)
+	p.SetSkipTidy(true)
+
+	results, err := p.UpdateAllContext(context.Background(), []scanner.PackageUpdate{
+		{Name: "example.com/b", CurrentVersion: "v1.0.0", TargetVersion: "v1.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Success || !results[0].RolledBack || !errors.Is(results[0].Error, ErrBuildFailed) {
+		t.Fatalf("results = %+v, want the update rolled back with ErrBuildFailed", results)
+	}
+	if required := p.requiredVersions(); required["example.com/b"] != "v1.0.0" {
+		t.Errorf("required = %v, want the original example.com/b v1.0.0", required)
+	}
+	if !errors.Is(p.BuildError(), ErrBuildFailed) {
+		t.Errorf("BuildError = %v, want ErrBuildFailed", p.BuildError())
+	}
+}
+
+func TestVerifyBuildKeepsEarlierUpdates(t *testing.T) {
+	p := newUnpatchedProject(t)
+	p.SetVerifyBuild(true)
+	p.SetSkipTidy(true)
+
+	results, err := p.UpdateAllContext(context.Background(), []scanner.PackageUpdate{
+		{Name: "example.com/a", CurrentVersion: "v1.0.0", TargetVersion: "v1.1.0"},
+	})
+	if err != nil || len(results) != 1 || !results[0].Success {
+		t.Fatalf("first call: results = %+v, error = %v; want example.com/a updated", results, err)
+	}
+
+	// A later call that breaks the build only undoes its own bumps
+	results, err = p.UpdateAllContext(context.Background(), []scanner.PackageUpdate{
+		{Name: "example.com/b", CurrentVersion: "v1.0.0", TargetVersion: "v1.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].RolledBack {
+		t.Fatalf("second call: results = %+v, want example.com/b rolled back", results)
+	}
+	if required := p.requiredVersions(); required["example.com/a"] != "v1.1.0" || required["example.com/b"] != "v1.0.0" {
+		t.Errorf("required = %v, want example.com/a v1.1.0 kept and example.com/b v1.0.0 restored", required)
+	}
+}
+
+func TestVerifyBuildRollbackFailure(t *testing.T) {
+	p := newUnpatchedProject(t)
+	p.SetVerifyBuild(true)
+	p.SetSkipTidy(true)
+
+	// A go.sum that can't be read breaks both the build and the rollback. It is replaced once
+	// UpdateAllContext has taken its snapshot, when the update is confirmed.
+	goSum := filepath.Join(p.projectPath, "go.sum")
+	p.SetConfirm(func(scanner.PackageUpdate) bool {
+		if err := os.Remove(goSum); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(goSum, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return true
+	})
+
+	results, err := p.UpdateAllContext(context.Background(), []scanner.PackageUpdate{
+		{Name: "example.com/b", CurrentVersion: "v1.0.0", TargetVersion: "v1.1.0"},
+	})
+	if !errors.Is(err, ErrRollbackFailed) {
+		t.Fatalf("error = %v, want ErrRollbackFailed", err)
+	}
+	if len(results) != 1 || results[0].RolledBack {
+		t.Errorf("results = %+v, want the update not marked as rolled back", results)
+	}
+}
+
+func TestRollbackBrokenKeepsUpdatesWhenBrokenBefore(t *testing.T) {
+	p := newBreakingProject(t)
+	// The project doesn't build regardless of its dependencies
+	writeFile(t, filepath.Join(p.projectPath, "broken.go"), "package main\n\nvar _ = undefined\n")
+	results := []UpdateResult{
+		{Update: scanner.PackageUpdate{Name: "example.com/a", TargetVersion: "v1.1.0"}, Success: true},
+		{Update: scanner.PackageUpdate{Name: "example.com/b", TargetVersion: "v1.1.0"}, Success: true},
+	}
+
+	results, err := p.RollbackBroken(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		if !result.Success || result.RolledBack {
+			t.Errorf("%s: success = %v, rolled back = %v; want the update kept", result.Update.Name, result.Success, result.RolledBack)
+		}
+	}
+	if required := p.requiredVersions(); required["example.com/a"] != "v1.1.0" || required["example.com/b"] != "v1.1.0" {
+		t.Errorf("required = %v, want both updates kept at v1.1.0", required)
+	}
+	if !errors.Is(p.BuildError(), ErrBuildFailed) {
+		t.Errorf("BuildError = %v, want ErrBuildFailed", p.BuildError())
+	}
+}
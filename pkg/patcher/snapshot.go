@@ -0,0 +1,49 @@
+package patcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot holds the contents of a project's go.mod and go.sum at a point in time
+type Snapshot struct {
+	GoMod []byte
+	GoSum []byte
+	// HasGoSum distinguishes a missing go.sum from an empty one
+	HasGoSum bool
+}
+
+// takeSnapshot reads the current go.mod and go.sum of the project
+func (p *Patcher) takeSnapshot() (Snapshot, error) {
+	var snap Snapshot
+
+	goMod, err := os.ReadFile(filepath.Join(p.projectPath, "go.mod"))
+	if err != nil {
+		return snap, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	snap.GoMod = goMod
+
+	goSum, err := os.ReadFile(filepath.Join(p.projectPath, "go.sum"))
+	switch {
+	case err == nil:
+		snap.GoSum = goSum
+		snap.HasGoSum = true
+	case !errors.Is(err, fs.ErrNotExist):
+		return snap, fmt.Errorf("failed to read go.sum: %w", err)
+	}
+
+	return snap, nil
+}
+
+// Original returns the go.mod and go.sum contents captured when the Patcher was created
+func (p *Patcher) Original() Snapshot {
+	return p.original
+}
+
+// Current returns the go.mod and go.sum contents as they are now
+func (p *Patcher) Current() (Snapshot, error) {
+	return p.takeSnapshot()
+}
@@ -0,0 +1,82 @@
+package patcher
+
+import (
+	"strings"
+)
+
+// TidyLevel classifies a message printed by go mod tidy
+type TidyLevel string
+
+const (
+	// TidyLevelInfo is progress chatter such as module downloads
+	TidyLevelInfo TidyLevel = "info"
+	// TidyLevelWarning is a message that did not cause tidy to fail
+	TidyLevelWarning TidyLevel = "warning"
+	// TidyLevelError is a message reported alongside a tidy failure
+	TidyLevelError TidyLevel = "error"
+)
+
+// TidyMessage is a single classified line of go mod tidy output
+type TidyMessage struct {
+	Level   TidyLevel
+	Message string
+}
+
+// tidyInfoPrefixes are progress messages printed by the go command that carry no diagnostic value
+var tidyInfoPrefixes = []string{
+	"go: downloading ",
+	"go: finding ",
+	"go: found ",
+	"go: added ",
+	"go: upgraded ",
+	"go: downgraded ",
+	"go: removed ",
+}
+
+// classifyTidyOutput splits go mod tidy output into classified messages.
+// Lines explicitly marked as warnings are always warnings. Any other non-progress
+// line is an error when tidy failed, and a warning when it succeeded.
+func classifyTidyOutput(output string, failed bool) []TidyMessage {
+	var messages []TidyMessage
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		level := TidyLevelWarning
+		switch {
+		case isTidyInfo(line):
+			level = TidyLevelInfo
+		case strings.Contains(strings.ToLower(line), "warning"):
+			level = TidyLevelWarning
+		case failed:
+			level = TidyLevelError
+		}
+
+		messages = append(messages, TidyMessage{Level: level, Message: line})
+	}
+
+	return messages
+}
+
+// isTidyInfo reports whether a tidy output line is informational progress output
+func isTidyInfo(line string) bool {
+	for _, prefix := range tidyInfoPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTidyProblems reports whether any of the messages is a warning or an error
+func HasTidyProblems(messages []TidyMessage) bool {
+	for _, msg := range messages {
+		if msg.Level != TidyLevelInfo {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package patcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/scanner"
+	"golang.org/x/mod/semver"
+)
+
+// ErrVersionNotPublished is returned when version verification is enabled and none of an
+// update's fix versions is published on the module proxy
+var ErrVersionNotPublished = errors.New("fix version is not published")
+
+// moduleVersions is the subset of `go list -m -versions -json` output used by grump
+type moduleVersions struct {
+	Path     string
+	Versions []string
+}
+
+// ListVersions returns the published versions of a module in semver order, as reported by
+// `go list -m -versions` run in the project directory (so GOPROXY and friends apply)
+func (p *Patcher) ListVersions(modPath string) ([]string, error) {
+	cmd := p.goCommand(p.projectPath, "list", "-m", "-versions", "-json", modPath)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %w: %s", modPath, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var info moduleVersions
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse versions of %s: %w", modPath, err)
+	}
+
+	return info.Versions, nil
+}
+
+// latestRelease returns the newest non-prerelease version, or the newest version if all are prereleases
+func latestRelease(versions []string) string {
+	latest := ""
+	for _, v := range versions {
+		if semver.Prerelease(v) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == "" && len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+	return latest
+}
+
+// AnnotateLatest sets LatestAvailable on each update whose module has a published release
+// newer than the selected fix version. It is informational only and never changes TargetVersion.
+// Modules whose version list can't be fetched are left unannotated.
+func (p *Patcher) AnnotateLatest(updates []scanner.PackageUpdate) {
+	latestByModule := make(map[string]string)
+
+	for i := range updates {
+		upd := &updates[i]
+
+		latest, ok := latestByModule[upd.Name]
+		if !ok {
+			versions, err := p.ListVersions(upd.Name)
+			if err != nil {
+				slog.Warn("Could not check latest version", "module", upd.Name, "error", err)
+			}
+			latest = latestRelease(versions)
+			latestByModule[upd.Name] = latest
+		}
+
+		if latest != "" && semver.Compare(latest, upd.TargetVersion) > 0 {
+			upd.LatestAvailable = latest
+		}
+	}
+}
+
+// publishedTarget checks that the update's target version is published and, if it isn't, walks
+// up the advisory's later fix versions to the first one that is. If the published versions
+// can't be listed, the target is kept unverified.
+func (p *Patcher) publishedTarget(upd scanner.PackageUpdate) (string, error) {
+	published, ok := p.publishedVersions[upd.Name]
+	if !ok {
+		versions, err := p.ListVersions(upd.Name)
+		if err != nil {
+			slog.Warn("Could not verify fix version", "module", upd.Name, "version", upd.TargetVersion, "error", err)
+		}
+		published = make(map[string]bool, len(versions))
+		for _, v := range versions {
+			published[v] = true
+		}
+		p.publishedVersions[upd.Name] = published
+	}
+	if len(published) == 0 {
+		return upd.TargetVersion, nil
+	}
+
+	candidates := []string{upd.TargetVersion}
+	for _, v := range upd.FixVersions {
+		if semver.Compare(v, upd.TargetVersion) > 0 {
+			candidates = append(candidates, v)
+		}
+	}
+	for _, v := range candidates {
+		if published[v] {
+			if v != upd.TargetVersion {
+				slog.Info("Fix version is not published, using the next fix version",
+					"module", upd.Name, "version", upd.TargetVersion, "using", v)
+			}
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s %s is not on the module proxy, and neither is any later fix version",
+		ErrVersionNotPublished, upd.Name, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,22 @@
+package patcher
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// SyncWorkspace runs go work sync in the directory of a go.work file, updating each member's
+// go.mod to the versions selected for the workspace as a whole. env are extra KEY=VALUE
+// variables for the go command, see Patcher.SetEnv.
+func SyncWorkspace(workspaceDir string, env ...string) error {
+	p := &Patcher{env: env}
+	cmd := p.goCommand(workspaceDir, "work", "sync")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go work sync: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/anchore/grype/grype"
+	grypeEvent "github.com/anchore/grype/grype/event"
+	"github.com/anchore/syft/syft"
+	syftEvent "github.com/anchore/syft/syft/event"
+	"github.com/wagoodman/go-partybus"
+)
+
+// Interval is how often progress is printed
+const Interval = time.Second
+
+// labels name the syft and grype events whose progress is reported
+var labels = map[partybus.EventType]string{
+	grypeEvent.UpdateVulnerabilityDatabase: "Updating vulnerability database",
+	syftEvent.CatalogerTaskStarted:         "Cataloging",
+}
+
+// progressable is the subset of go-progress's Progressable that is reported
+type progressable interface {
+	Current() int64
+	Size() int64
+	// Error returns a non-nil error, usually progress.ErrCompleted, once the task is done
+	Error() error
+}
+
+// stager describes the current stage of a task, such as "42 packages"
+type stager interface {
+	Stage() string
+}
+
+// task is a running syft or grype task and the last progress line printed for it
+type task struct {
+	label    string
+	progress progressable
+	last     string
+}
+
+// Monitor prints the progress of syft and grype tasks, such as cataloging packages and
+// downloading the vulnerability database
+type Monitor struct {
+	w     io.Writer
+	sub   *partybus.Subscription
+	mu    sync.Mutex
+	tasks []*task
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Start routes syft and grype events to a new monitor that prints progress to w every
+// Interval. The buses are process-wide, so only one monitor should run at a time.
+func Start(w io.Writer) *Monitor {
+	bus := partybus.NewBus()
+	syft.SetBus(bus)
+	grype.SetBus(bus)
+
+	m := &Monitor{
+		w:    w,
+		sub:  bus.Subscribe(),
+		done: make(chan struct{}),
+	}
+	m.wg.Add(2)
+	go m.collect()
+	go m.print()
+	return m
+}
+
+// Stop prints the final state of unfinished tasks and stops reporting
+func (m *Monitor) Stop() {
+	_ = m.sub.Unsubscribe()
+	close(m.done)
+	m.wg.Wait()
+	m.flush()
+}
+
+// collect tracks tasks as their start events arrive
+func (m *Monitor) collect() {
+	defer m.wg.Done()
+	events := m.sub.Events()
+	for {
+		select {
+		case <-m.done:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			label, known := labels[e.Type]
+			p, isProgress := e.Value.(progressable)
+			if !known || !isProgress {
+				continue
+			}
+			m.mu.Lock()
+			m.tasks = append(m.tasks, &task{label: label, progress: p})
+			m.mu.Unlock()
+		}
+	}
+}
+
+// print reports task progress every Interval until stopped
+func (m *Monitor) print() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.flush()
+		}
+	}
+}
+
+// flush prints a line for each task whose progress changed and forgets finished tasks
+func (m *Monitor) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	running := m.tasks[:0]
+	for _, t := range m.tasks {
+		line := describe(t)
+		if line != t.last {
+			fmt.Fprintln(m.w, line)
+			t.last = line
+		}
+		if t.progress.Error() == nil {
+			running = append(running, t)
+		}
+	}
+	m.tasks = running
+}
+
+// describe renders a task's progress as a percentage when its size is known, otherwise as its
+// stage or a count
+func describe(t *task) string {
+	line := t.label
+	stage := ""
+	if s, ok := t.progress.(stager); ok {
+		stage = s.Stage()
+	}
+
+	current, size := t.progress.Current(), t.progress.Size()
+	switch {
+	case t.progress.Error() != nil:
+		return line + ": done"
+	case size > 0 && stage != "":
+		return fmt.Sprintf("%s: %d%% (%s)", line, current*100/size, stage)
+	case size > 0:
+		return fmt.Sprintf("%s: %d%%", line, current*100/size)
+	case stage != "":
+		return line + ": " + stage
+	default:
+		return fmt.Sprintf("%s: %d", line, current)
+	}
+}
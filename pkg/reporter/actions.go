@@ -0,0 +1,181 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+	"golang.org/x/mod/semver"
+)
+
+// actionKind orders actions by the effort they require from a human
+type actionKind int
+
+const (
+	actionBump actionKind = iota
+	actionManual
+	actionTrack
+)
+
+// action is a single deduplicated to-do item in the actions summary
+type action struct {
+	kind       actionKind
+	subject    string         // package path, or vulnerability ID for track actions
+	version    string         // target version for bump and manual actions
+	reason     string         // why a manual action is needed
+	packages   []string       // affected packages for track actions
+	severities map[string]int // vulnerability counts by severity label
+}
+
+// maxSeverityRank returns the rank of the most severe vulnerability covered by the action
+func (a *action) maxSeverityRank() int {
+	maxRank := 0
+	for severity := range a.severities {
+		if rank := scanner.SeverityRank(severity); rank > maxRank {
+			maxRank = rank
+		}
+	}
+	return maxRank
+}
+
+// severitySummary formats severity counts most severe first, e.g. "2 Critical, 1 High"
+func (a *action) severitySummary() string {
+	labels := make([]string, 0, len(a.severities))
+	for severity := range a.severities {
+		labels = append(labels, severity)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		ri, rj := scanner.SeverityRank(labels[i]), scanner.SeverityRank(labels[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return labels[i] < labels[j]
+	})
+
+	parts := make([]string, 0, len(labels))
+	for _, severity := range labels {
+		parts = append(parts, fmt.Sprintf("%d %s", a.severities[severity], severity))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildActions synthesizes fixable, failed, and unfixable findings into a prioritized list
+// ordered by severity, then effort, then name
+func buildActions(updates []scanner.PackageUpdate, results []patcher.UpdateResult, unfixable []scanner.UnfixableVulnerability) []*action {
+	// Collect per-package outcome of the patch run
+	updated := make(map[string]string)
+	failed := make(map[string]error)
+	skipped := make(map[string]string)
+	for _, result := range results {
+		if result.Skipped {
+			skipped[result.Update.Name] = result.Reason
+		} else if result.Success {
+			if current, ok := updated[result.Update.Name]; !ok || semver.Compare(result.Update.TargetVersion, current) > 0 {
+				updated[result.Update.Name] = result.Update.TargetVersion
+			}
+		} else {
+			failed[result.Update.Name] = result.Error
+		}
+	}
+
+	byPackage := make(map[string]*action)
+	var actions []*action
+	for _, update := range updates {
+		a, ok := byPackage[update.Name]
+		if !ok {
+			a = &action{kind: actionBump, subject: update.Name, severities: make(map[string]int)}
+			byPackage[update.Name] = a
+			actions = append(actions, a)
+		}
+		a.severities[update.SeverityForGating()]++
+		if semver.Compare(update.TargetVersion, a.version) > 0 || a.version == "" {
+			a.version = update.TargetVersion
+		}
+
+		if version, ok := updated[update.Name]; ok {
+			a.version = version
+			continue
+		}
+		if reason, ok := skipped[update.Name]; ok {
+			a.kind = actionManual
+			a.reason = reason
+			continue
+		}
+		if err, ok := failed[update.Name]; ok {
+			a.kind = actionManual
+			a.reason = "update failed"
+			if semver.Major(update.CurrentVersion) != semver.Major(update.TargetVersion) {
+				a.reason = fmt.Sprintf("requires %s, no auto-fix", semver.Major(update.TargetVersion))
+			} else if err != nil {
+				a.reason = "update failed: " + firstLine(err.Error())
+			}
+		}
+	}
+
+	byVuln := make(map[string]*action)
+	for _, vuln := range unfixable {
+		a, ok := byVuln[vuln.VulnID]
+		if !ok {
+			a = &action{kind: actionTrack, subject: vuln.VulnID, severities: map[string]int{vuln.Severity: 1}}
+			byVuln[vuln.VulnID] = a
+			actions = append(actions, a)
+		}
+		if !containsString(a.packages, vuln.Name) {
+			a.packages = append(a.packages, vuln.Name)
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		ri, rj := actions[i].maxSeverityRank(), actions[j].maxSeverityRank()
+		if ri != rj {
+			return ri > rj
+		}
+		if actions[i].kind != actions[j].kind {
+			return actions[i].kind < actions[j].kind
+		}
+		return actions[i].subject < actions[j].subject
+	})
+
+	return actions
+}
+
+// reportActions outputs a concise, prioritized list of actions for humans
+func (r *Reporter) reportActions(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	actions := buildActions(updates, results, r.Unfixable)
+	if len(actions) == 0 {
+		fmt.Fprintln(r.writer, "No actions needed.")
+		return nil
+	}
+
+	fmt.Fprintln(r.writer, "Actions needed:")
+	for i, a := range actions {
+		switch a.kind {
+		case actionBump:
+			fmt.Fprintf(r.writer, "%d. Bump %s to %s (fixes %s)\n", i+1, a.subject, a.version, a.severitySummary())
+		case actionManual:
+			fmt.Fprintf(r.writer, "%d. Manual: bump %s to %s (%s, %s)\n", i+1, a.subject, a.version, a.severitySummary(), a.reason)
+		case actionTrack:
+			fmt.Fprintf(r.writer, "%d. Track: %s has no fix yet (%s, affects %s)\n", i+1, a.subject, a.severitySummary(), strings.Join(a.packages, ", "))
+		}
+	}
+
+	return nil
+}
+
+// firstLine returns the first line of a possibly multi-line message
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// Fix statuses of a package affected by an advisory
+const (
+	StatusFixed   = "fixed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	StatusPending = "pending"
+	StatusNoFix   = "no-fix"
+)
+
+// AdvisoryReport lists a single advisory with every package it affects
+type AdvisoryReport struct {
+	ID       string            `json:"id"`
+	Severity string            `json:"severity"`
+	Packages []AdvisoryPackage `json:"packages"`
+}
+
+// AdvisoryPackage is a package affected by an advisory and its fix status
+type AdvisoryPackage struct {
+	Package        string `json:"package"`
+	CurrentVersion string `json:"current_version"`
+	TargetVersion  string `json:"target_version,omitempty"`
+	Status         string `json:"status"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// packageStatus determines the fix status of a package from the update results.
+// A package is fixed if any of its updates succeeded.
+func packageStatus(name string, results []patcher.UpdateResult) (string, string) {
+	status, detail := StatusPending, ""
+	for _, result := range results {
+		if result.Update.Name != name {
+			continue
+		}
+		switch {
+		case result.Success:
+			return StatusFixed, ""
+		case result.Skipped:
+			status, detail = StatusSkipped, result.Reason
+		case status != StatusSkipped:
+			status = StatusFailed
+			if result.Error != nil {
+				detail = firstLine(result.Error.Error())
+			}
+		}
+	}
+	return status, detail
+}
+
+// buildAdvisories groups findings by advisory, the inverse of the per-package layout
+func buildAdvisories(updates []scanner.PackageUpdate, results []patcher.UpdateResult, unfixable []scanner.UnfixableVulnerability) []AdvisoryReport {
+	byID := make(map[string]*AdvisoryReport)
+	var ids []string
+
+	advisory := func(id, severity string) *AdvisoryReport {
+		a, ok := byID[id]
+		if !ok {
+			a = &AdvisoryReport{ID: id, Severity: severity}
+			byID[id] = a
+			ids = append(ids, id)
+		}
+		if scanner.SeverityRank(severity) > scanner.SeverityRank(a.Severity) {
+			a.Severity = severity
+		}
+		return a
+	}
+
+	seen := make(map[string]bool)
+	for _, update := range updates {
+		key := update.VulnID + "\x00" + update.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		status, detail := packageStatus(update.Name, results)
+		a := advisory(update.VulnID, update.SeverityForGating())
+		a.Packages = append(a.Packages, AdvisoryPackage{
+			Package:        update.Name,
+			CurrentVersion: update.CurrentVersion,
+			TargetVersion:  update.TargetVersion,
+			Status:         status,
+			Detail:         detail,
+		})
+	}
+
+	for _, vuln := range unfixable {
+		key := vuln.VulnID + "\x00" + vuln.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		a := advisory(vuln.VulnID, vuln.Severity)
+		a.Packages = append(a.Packages, AdvisoryPackage{
+			Package:        vuln.Name,
+			CurrentVersion: vuln.Version,
+			Status:         StatusNoFix,
+			Detail:         vuln.FixState,
+		})
+	}
+
+	advisories := make([]AdvisoryReport, 0, len(ids))
+	for _, id := range ids {
+		a := byID[id]
+		sort.Slice(a.Packages, func(i, j int) bool {
+			return a.Packages[i].Package < a.Packages[j].Package
+		})
+		advisories = append(advisories, *a)
+	}
+
+	sort.SliceStable(advisories, func(i, j int) bool {
+		ri, rj := scanner.SeverityRank(advisories[i].Severity), scanner.SeverityRank(advisories[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return advisories[i].ID < advisories[j].ID
+	})
+
+	return advisories
+}
+
+// reportAdvisoryText outputs results grouped by advisory in human-readable text format
+func (r *Reporter) reportAdvisoryText(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	advisories := buildAdvisories(updates, results, r.Unfixable)
+	if len(advisories) == 0 {
+		fmt.Fprintln(r.writer, "No vulnerabilities found.")
+		return nil
+	}
+
+	fmt.Fprintf(r.writer, "Found %d advisories:\n", len(advisories))
+	for _, a := range advisories {
+		fmt.Fprintf(r.writer, "\n%s (%s)\n", a.ID, a.Severity)
+		for _, p := range a.Packages {
+			fmt.Fprintf(r.writer, "  - %s %s", p.Package, p.CurrentVersion)
+			if p.TargetVersion != "" {
+				fmt.Fprintf(r.writer, " → %s", p.TargetVersion)
+			}
+			fmt.Fprintf(r.writer, ": %s", p.Status)
+			if p.Detail != "" {
+				fmt.Fprintf(r.writer, " (%s)", p.Detail)
+			}
+			fmt.Fprintln(r.writer)
+		}
+	}
+
+	stats := AnalyzeResults(updates, results)
+	fmt.Fprintf(r.writer, "\nSummary: Updated %d package(s) to fix %d vulnerabilities\n", stats.PackagesUpdated, stats.VulnerabilitiesFixed)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"strconv"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// csvHeader names the columns of the CSV report
+var csvHeader = []string{"package", "current", "target", "vuln_id", "severity", "success", "error"}
+
+// reportCSV outputs one row per vulnerability for spreadsheet triage. Vulnerabilities without
+// an available fix have no target version. encoding/csv quotes fields containing commas,
+// quotes, or newlines, such as multi-line update errors.
+func (r *Reporter) reportCSV(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	w := csv.NewWriter(r.writer)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		status, detail := packageStatus(update.Name, results)
+		errText := updateError(update.Name, results)
+		if errText == "" && status != StatusFixed {
+			errText = detail
+		}
+		row := []string{
+			update.Name,
+			update.CurrentVersion,
+			update.TargetVersion,
+			update.VulnID,
+			update.SeverityForGating(),
+			strconv.FormatBool(status == StatusFixed),
+			errText,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, vuln := range r.Unfixable {
+		row := []string{vuln.Name, vuln.Version, "", vuln.VulnID, vuln.Severity, "false", "no fix available"}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
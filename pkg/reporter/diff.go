@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/divolgin/grump/pkg/state"
+)
+
+// ReportDiff compares the findings of a report against a baseline report
+type ReportDiff struct {
+	// Baseline is where the baseline report was loaded from
+	Baseline  string          `json:"baseline,omitempty"`
+	Added     []state.Finding `json:"added"`
+	Removed   []state.Finding `json:"removed"`
+	Unchanged []state.Finding `json:"unchanged"`
+}
+
+// Empty reports whether no findings were added or removed since the baseline
+func (d *ReportDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// LoadReport reads a JSON report written by grump, for use as a baseline
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// findings lists every vulnerability a report found, fixable or not, keyed by module and
+// vulnerability ID. Whether grump fixed it in that run doesn't matter.
+func (rep *Report) findings() map[string]state.Finding {
+	findings := make(map[string]state.Finding)
+	for _, update := range rep.Updates {
+		for _, vulnID := range append([]string{update.VulnID}, update.VulnIDs...) {
+			findings[update.Package+"@"+vulnID] = state.Finding{
+				Package:  update.Package,
+				Version:  update.CurrentVersion,
+				VulnID:   vulnID,
+				Severity: update.EffectiveSeverity,
+			}
+		}
+	}
+	for _, vuln := range rep.Unfixable {
+		findings[vuln.Package+"@"+vuln.VulnID] = state.Finding{
+			Package:  vuln.Package,
+			Version:  vuln.Version,
+			VulnID:   vuln.VulnID,
+			Severity: vuln.Severity,
+		}
+	}
+	return findings
+}
+
+// Diff compares the vulnerabilities found in current against those in baseline: added ones
+// are new since the baseline, removed ones have been fixed or are no longer detected
+func Diff(baseline, current *Report) *ReportDiff {
+	before, after := baseline.findings(), current.findings()
+	diff := &ReportDiff{
+		Added:     []state.Finding{},
+		Removed:   []state.Finding{},
+		Unchanged: []state.Finding{},
+	}
+
+	for key, f := range after {
+		if _, ok := before[key]; ok {
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			diff.Added = append(diff.Added, f)
+		}
+	}
+	for key, f := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+
+	for _, findings := range [][]state.Finding{diff.Added, diff.Removed, diff.Unchanged} {
+		sort.Slice(findings, func(i, j int) bool {
+			if findings[i].Package != findings[j].Package {
+				return findings[i].Package < findings[j].Package
+			}
+			return findings[i].VulnID < findings[j].VulnID
+		})
+	}
+	return diff
+}
+
+// reportBaselineText lists the findings added and removed since the baseline report
+func (r *Reporter) reportBaselineText(diff *ReportDiff) {
+	fmt.Fprintf(r.writer, "\nCompared to baseline %s: %d added, %d removed, %d unchanged\n",
+		diff.Baseline, len(diff.Added), len(diff.Removed), len(diff.Unchanged))
+	for _, group := range []struct {
+		marker   string
+		findings []state.Finding
+	}{
+		{"+", diff.Added},
+		{"-", diff.Removed},
+	} {
+		for _, f := range group.findings {
+			fmt.Fprintf(r.writer, "  %s %s %s (%s, %s)\n", group.marker, f.Package, f.Version, f.VulnID, f.Severity)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// ExplanationReport is the rationale for an update's target version, included with -explain
+type ExplanationReport struct {
+	FixState         string   `json:"fix_state"`
+	AdvisoryVersions []string `json:"advisory_fix_versions"`
+	FixVersions      []string `json:"fix_versions"`
+	Strategy         string   `json:"strategy"`
+	Selected         string   `json:"selected"`
+	SelectedFrom     string   `json:"selected_from"`
+	Normalized       bool     `json:"normalized"`
+	// Reasons are the same rationale as sentences, as shown in the text report
+	Reasons []string `json:"reasons"`
+}
+
+// explainUpdate returns the rationale for an update's target version, nil if unknown
+func explainUpdate(update scanner.PackageUpdate) *ExplanationReport {
+	e := update.Explanation
+	if e == nil {
+		return nil
+	}
+	return &ExplanationReport{
+		FixState:         e.FixState,
+		AdvisoryVersions: e.AdvisoryVersions,
+		FixVersions:      update.FixVersions,
+		Strategy:         string(e.Strategy),
+		Selected:         e.Selected,
+		SelectedFrom:     e.SelectedFrom,
+		Normalized:       e.Normalized(),
+		Reasons:          explanationReasons(update),
+	}
+}
+
+// explanationReasons describes how an update's target version was chosen
+func explanationReasons(update scanner.PackageUpdate) []string {
+	e := update.Explanation
+	reasons := []string{
+		fmt.Sprintf("%s is in fix state %s with fix versions %s",
+			update.VulnID, e.FixState, strings.Join(e.AdvisoryVersions, ", ")),
+	}
+
+	selected := fmt.Sprintf("the %s strategy selected %s", e.Strategy, e.Selected)
+	if len(update.FixVersions) > 1 {
+		selected += fmt.Sprintf(" from the valid versions %s", strings.Join(update.FixVersions, ", "))
+	}
+	reasons = append(reasons, selected)
+
+	if e.Normalized() {
+		reasons = append(reasons, fmt.Sprintf("%s was normalized from %s to match the module's version scheme",
+			e.Selected, e.SelectedFrom))
+	}
+	if len(update.VulnIDs) > 1 {
+		reasons = append(reasons, fmt.Sprintf("%s is the highest version required by the %d vulnerabilities of this package",
+			e.Selected, len(update.VulnIDs)))
+	}
+	if update.TargetVersion != e.Selected {
+		reasons = append(reasons, fmt.Sprintf("%s is not published, so the next fix version %s is used",
+			e.Selected, update.TargetVersion))
+	}
+	return reasons
+}
+
+// reportExplanationText prints the rationale for an update below its line in the text report
+func (r *Reporter) reportExplanationText(update scanner.PackageUpdate) {
+	if update.Explanation == nil {
+		return
+	}
+	for _, reason := range explanationReasons(update) {
+		fmt.Fprintf(r.writer, "      why: %s\n", reason)
+	}
+}
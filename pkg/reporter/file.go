@@ -0,0 +1,82 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile renders the report in the given format to path, creating parent directories as
+// needed. The report is written to a temporary file in the same directory and renamed into
+// place, so readers never see a partially written file.
+func (rep *Report) WriteFile(path, format string) error {
+	return writeFileAtomic(path, "report", func(w io.Writer) error {
+		return rep.Write(w, format)
+	})
+}
+
+// FileNames are the files WriteDir writes each output format to. The template format needs a
+// template and is left out.
+var FileNames = map[string]string{
+	"text":     "report.txt",
+	"json":     "report.json",
+	"actions":  "report.actions.txt",
+	"nexus-iq": "report.nexus-iq.json",
+	"tuples":   "report.tuples.txt",
+	"sarif":    "report.sarif",
+	"junit":    "report.junit.xml",
+	"markdown": "report.md",
+	"vex":      "report.vex.json",
+	"csv":      "report.csv",
+	"html":     "report.html",
+}
+
+// WriteDir renders the report in every format of FileNames to its file in dir, creating dir
+// as needed, and returns the paths written
+func (rep *Report) WriteDir(dir string) ([]string, error) {
+	var paths []string
+	for _, format := range Formats {
+		name, ok := FileNames[format]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := rep.WriteFile(path, format); err != nil {
+			return paths, fmt.Errorf("%s: %w", format, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeFileAtomic writes path with write through a temporary file in the same directory that
+// is renamed into place. what names the file in errors.
+func writeFileAtomic(path, what string, write func(io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", what, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s file: %w", what, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", what, err)
+	}
+	return nil
+}
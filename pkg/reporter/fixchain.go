@@ -0,0 +1,30 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FixChain is the sequence of versions a module was patched through when its fix version was
+// itself vulnerable, see grump.Options.MaxPasses
+type FixChain struct {
+	Package string `json:"package"`
+	// Versions starts with the version before patching, followed by the target of each pass
+	Versions []string `json:"versions"`
+	// Converged is false when the last version is still vulnerable after the final pass
+	Converged bool `json:"converged"`
+	// Remaining are the vulnerabilities of the last version when it didn't converge
+	Remaining []string `json:"remaining,omitempty"`
+}
+
+// reportFixChainsText lists the modules that needed more than one bump to become fixed
+func (r *Reporter) reportFixChainsText() {
+	fmt.Fprintf(r.writer, "\n%d module(s) needed more than one pass because a fix version was vulnerable:\n", len(r.FixChains))
+	for _, chain := range r.FixChains {
+		fmt.Fprintf(r.writer, "  - %s %s", chain.Package, strings.Join(chain.Versions, " → "))
+		if !chain.Converged {
+			fmt.Fprintf(r.writer, " (still vulnerable: %s)", strings.Join(chain.Remaining, ", "))
+		}
+		fmt.Fprintln(r.writer)
+	}
+}
@@ -0,0 +1,120 @@
+package reporter
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlTemplates embed.FS
+
+// htmlTemplate renders the self-contained HTML report
+var htmlTemplate = template.Must(template.ParseFS(htmlTemplates, "templates/report.html.tmpl"))
+
+// htmlSeverities are the severity sections of the HTML report, most severe first
+var htmlSeverities = []string{"Critical", "High", "Medium", "Low", "Negligible", "Unknown"}
+
+// htmlPage is the data rendered by the HTML template
+type htmlPage struct {
+	Generated string
+	Version   string
+	DryRun    bool
+	Fixed     int
+	Failed    int
+	Skipped   int
+	Total     int
+	Unfixable int
+	Sections  []htmlSection
+}
+
+// htmlSection is the findings of one severity, collapsible in the page
+type htmlSection struct {
+	Severity string
+	Class    string
+	// Open expands the section when the page loads; only the most severe findings are open
+	Open bool
+	Rows []htmlRow
+}
+
+// htmlRow is a single finding
+type htmlRow struct {
+	Package        string
+	CurrentVersion string
+	TargetVersion  string
+	VulnID         string
+	Severity       string
+	Status         string
+	Detail         string
+}
+
+// htmlSeverity returns the section a severity label belongs to
+func htmlSeverity(severity string) string {
+	for _, s := range htmlSeverities {
+		if strings.EqualFold(s, severity) {
+			return s
+		}
+	}
+	return "Unknown"
+}
+
+// reportHTML outputs results as a self-contained HTML page for sharing, with a summary header
+// and a collapsible section per severity
+func (r *Reporter) reportHTML(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	stats := AnalyzeResults(updates, results)
+	page := htmlPage{
+		Generated: time.Now().UTC().Format(time.RFC1123),
+		Version:   r.toolVersion(),
+		DryRun:    r.DryRun,
+		Fixed:     stats.VulnerabilitiesFixed,
+		Failed:    stats.VulnerabilitiesFailed,
+		Skipped:   stats.VulnerabilitiesSkipped,
+		Total:     len(updates),
+		Unfixable: len(r.Unfixable),
+	}
+
+	rows := make(map[string][]htmlRow)
+	for _, update := range updates {
+		status, detail := packageStatus(update.Name, results)
+		section := htmlSeverity(update.SeverityForGating())
+		rows[section] = append(rows[section], htmlRow{
+			Package:        update.Name,
+			CurrentVersion: update.CurrentVersion,
+			TargetVersion:  update.TargetVersion,
+			VulnID:         update.VulnID,
+			Severity:       formatSeverity(update),
+			Status:         status,
+			Detail:         detail,
+		})
+	}
+	for _, vuln := range r.Unfixable {
+		section := htmlSeverity(vuln.Severity)
+		rows[section] = append(rows[section], htmlRow{
+			Package:        vuln.Name,
+			CurrentVersion: vuln.Version,
+			VulnID:         vuln.VulnID,
+			Severity:       vuln.Severity,
+			Status:         StatusNoFix,
+			Detail:         fmt.Sprintf("fix state: %s", vuln.FixState),
+		})
+	}
+
+	for _, severity := range htmlSeverities {
+		if len(rows[severity]) == 0 {
+			continue
+		}
+		page.Sections = append(page.Sections, htmlSection{
+			Severity: severity,
+			Class:    strings.ToLower(severity),
+			Open:     len(page.Sections) == 0,
+			Rows:     rows[severity],
+		})
+	}
+
+	return htmlTemplate.Execute(r.writer, page)
+}
@@ -0,0 +1,106 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// junitTestSuite is a JUnit XML test suite with one test case per vulnerability
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single vulnerability; it fails when the module wasn't patched
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure describes why a vulnerability was not fixed
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped describes why a vulnerability was intentionally left alone
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// updateError returns the full error of the failed update for a package, if any
+func updateError(name string, results []patcher.UpdateResult) string {
+	for _, result := range results {
+		if result.Update.Name == name && !result.Success && !result.Skipped && result.Error != nil {
+			return result.Error.Error()
+		}
+	}
+	return ""
+}
+
+// reportJUnit outputs results as a JUnit XML test suite for CI dashboards.
+// Each vulnerability is a test case named by its ID and classed by its module. Fixed
+// vulnerabilities pass; failed or pending ones fail with the update error; skipped ones and
+// those without an available fix are reported as skipped.
+func (r *Reporter) reportJUnit(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	suite := junitTestSuite{Name: "grump"}
+
+	for _, update := range updates {
+		tc := junitTestCase{
+			ClassName: update.Name,
+			Name:      fmt.Sprintf("%s (%s)", update.VulnID, update.SeverityForGating()),
+		}
+
+		status, detail := packageStatus(update.Name, results)
+		switch status {
+		case StatusFixed:
+		case StatusSkipped:
+			tc.Skipped = &junitSkipped{Message: detail}
+			suite.Skipped++
+		default:
+			message := fmt.Sprintf("%s %s was not updated to %s", update.Name, update.CurrentVersion, update.TargetVersion)
+			if detail != "" {
+				message = detail
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    status,
+				Text:    updateError(update.Name, results),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, vuln := range r.Unfixable {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: vuln.Name,
+			Name:      fmt.Sprintf("%s (%s)", vuln.VulnID, vuln.Severity),
+			Skipped:   &junitSkipped{Message: "no fix available (" + vuln.FixState + ")"},
+		})
+		suite.Skipped++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := fmt.Fprint(r.writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(r.writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(r.writer)
+	return err
+}
@@ -0,0 +1,108 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// markdownEscaper escapes characters that GitHub-flavored Markdown would interpret
+// inside table cells
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// markdownEscape escapes text for a GitHub-flavored Markdown table cell
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(strings.ReplaceAll(s, "\n", " "))
+}
+
+// markdownStatus renders an update status as a table cell
+func markdownStatus(status string) string {
+	switch status {
+	case StatusFixed:
+		return "✓ fixed"
+	case StatusFailed:
+		return "✗ failed"
+	case StatusSkipped:
+		return "– skipped"
+	case StatusNoFix:
+		return "✗ no fix"
+	default:
+		return status
+	}
+}
+
+// reportMarkdown outputs results as a GitHub-flavored Markdown table suitable for PR comments,
+// followed by a one-line summary
+func (r *Reporter) reportMarkdown(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	fmt.Fprintln(r.writer, "### grump vulnerability report")
+	fmt.Fprintln(r.writer)
+
+	if len(updates) == 0 && len(r.Unfixable) == 0 {
+		fmt.Fprintln(r.writer, "No vulnerabilities found.")
+		return nil
+	}
+
+	fmt.Fprintln(r.writer, "| Package | Current → Target | Severity | Vulnerability | Status |")
+	fmt.Fprintln(r.writer, "| --- | --- | --- | --- | --- |")
+	for _, update := range updates {
+		status, _ := packageStatus(update.Name, results)
+		fmt.Fprintf(r.writer, "| %s | %s → %s | %s | %s | %s |\n",
+			markdownEscape(update.Name),
+			markdownEscape(update.CurrentVersion),
+			markdownEscape(update.TargetVersion),
+			markdownEscape(formatSeverity(update)),
+			markdownEscape(update.VulnID),
+			markdownStatus(status),
+		)
+	}
+	for _, vuln := range r.Unfixable {
+		fmt.Fprintf(r.writer, "| %s | %s → – | %s | %s | %s |\n",
+			markdownEscape(vuln.Name),
+			markdownEscape(vuln.Version),
+			markdownEscape(vuln.Severity),
+			markdownEscape(vuln.VulnID),
+			markdownStatus(StatusNoFix),
+		)
+	}
+
+	stats := AnalyzeResults(updates, results)
+	fmt.Fprintln(r.writer)
+	if r.DryRun {
+		fmt.Fprint(r.writer, "**Summary (dry run):** ")
+	} else {
+		fmt.Fprint(r.writer, "**Summary:** ")
+	}
+	fmt.Fprintf(r.writer, "%d of %d fixable vulnerabilities fixed by updating %d package(s)",
+		stats.VulnerabilitiesFixed, len(updates), stats.PackagesUpdated)
+	if stats.VulnerabilitiesFailed > 0 {
+		fmt.Fprintf(r.writer, ", %d not fixed", stats.VulnerabilitiesFailed)
+	}
+	if stats.VulnerabilitiesSkipped > 0 {
+		fmt.Fprintf(r.writer, ", %d skipped", stats.VulnerabilitiesSkipped)
+	}
+	if len(r.Unfixable) > 0 {
+		fmt.Fprintf(r.writer, ", %d without a fix", len(r.Unfixable))
+	}
+	fmt.Fprintln(r.writer, ".")
+
+	if r.Diff != "" {
+		fmt.Fprintln(r.writer)
+		fmt.Fprintln(r.writer, "```diff")
+		fmt.Fprint(r.writer, r.Diff)
+		fmt.Fprintln(r.writer, "```")
+	}
+
+	return nil
+}
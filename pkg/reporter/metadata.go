@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ciMetadataEnv maps well-known CI environment variables to report metadata keys.
+// The first variable found for a key wins.
+var ciMetadataEnv = []struct {
+	key string
+	env string
+}{
+	// GitHub Actions
+	{"git_sha", "GITHUB_SHA"},
+	{"branch", "GITHUB_REF_NAME"},
+	{"build_id", "GITHUB_RUN_ID"},
+	{"repository", "GITHUB_REPOSITORY"},
+	// GitLab CI
+	{"git_sha", "CI_COMMIT_SHA"},
+	{"branch", "CI_COMMIT_REF_NAME"},
+	{"build_id", "CI_PIPELINE_ID"},
+	{"repository", "CI_PROJECT_PATH"},
+	// Jenkins and other generic CI systems
+	{"git_sha", "GIT_COMMIT"},
+	{"branch", "GIT_BRANCH"},
+	{"build_id", "BUILD_ID"},
+}
+
+// DetectCIMetadata returns report metadata derived from common CI environment variables
+func DetectCIMetadata() map[string]string {
+	metadata := make(map[string]string)
+	for _, entry := range ciMetadataEnv {
+		if _, exists := metadata[entry.key]; exists {
+			continue
+		}
+		if value := os.Getenv(entry.env); value != "" {
+			metadata[entry.key] = value
+		}
+	}
+	return metadata
+}
+
+// ParseMetadata parses key=value pairs into a metadata map
+func ParseMetadata(pairs []string) (map[string]string, error) {
+	metadata := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid metadata %q: expected key=value", pair)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
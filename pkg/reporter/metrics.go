@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// metricSeverities are the severity label values of the metrics, from least to most severe.
+// Every one is written, with zero counts too, so that series don't disappear between runs.
+var metricSeverities = []string{"unknown", "negligible", "low", "medium", "high", "critical"}
+
+// severityCounts counts vulnerabilities by metric severity label
+type severityCounts map[string]int
+
+// add counts a vulnerability of the given grype severity
+func (c severityCounts) add(severity string) {
+	label := strings.ToLower(severity)
+	if _, ok := c[label]; !ok {
+		label = "unknown"
+	}
+	c[label]++
+}
+
+// newSeverityCounts returns zero counts for every metric severity
+func newSeverityCounts() severityCounts {
+	counts := make(severityCounts, len(metricSeverities))
+	for _, severity := range metricSeverities {
+		counts[severity] = 0
+	}
+	return counts
+}
+
+// WriteMetrics writes the Go module vulnerability counts of the report to w as Prometheus
+// text exposition format gauges labeled by severity: all vulnerabilities found, with or
+// without a fix, and those whose update was applied or failed
+func (rep *Report) WriteMetrics(w io.Writer) error {
+	total, fixed, failed := newSeverityCounts(), newSeverityCounts(), newSeverityCounts()
+
+	// Vulnerabilities are fixed by their package's update, like AnalyzeResults counts them
+	updated, hasFailed := make(map[string]bool), make(map[string]bool)
+	for _, result := range rep.results {
+		switch {
+		case result.Success:
+			updated[result.Update.Name] = true
+		case !result.Skipped:
+			hasFailed[result.Update.Name] = true
+		}
+	}
+	for _, update := range rep.updates {
+		total.add(update.Severity)
+		if updated[update.Name] {
+			fixed.add(update.Severity)
+		} else if hasFailed[update.Name] {
+			failed.add(update.Severity)
+		}
+	}
+	for _, vuln := range rep.Unfixable {
+		total.add(vuln.Severity)
+	}
+
+	metrics := []struct {
+		name   string
+		help   string
+		counts severityCounts
+	}{
+		{"grump_vulnerabilities_total", "Go module vulnerabilities found, with or without a fix.", total},
+		{"grump_fixed_total", "Vulnerabilities fixed by an applied update.", fixed},
+		{"grump_failed_total", "Vulnerabilities whose update failed.", failed},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+		for _, severity := range metricSeverities {
+			if _, err := fmt.Fprintf(w, "%s{severity=%q} %d\n", metric.name, severity, metric.counts[severity]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMetricsFile writes the metrics of the report to path, see WriteMetrics. Like
+// WriteFile, it replaces the file atomically, so a textfile collector never reads a
+// partially written file.
+func (rep *Report) WriteMetricsFile(path string) error {
+	return writeFileAtomic(path, "metrics", rep.WriteMetrics)
+}
@@ -0,0 +1,135 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ModuleOutput is the report of a single module in a multi-module run
+type ModuleOutput struct {
+	// Path is the module directory relative to the scanned root
+	Path string
+	// Output is the module's report in the run's output format, empty if there was nothing to report
+	Output []byte
+	Stats  ResultStats
+	// Failed is set when the module could not be scanned or patched
+	Failed bool
+}
+
+// ModulesReport is the JSON report of a multi-module run
+type ModulesReport struct {
+	Modules []ModuleReport `json:"modules"`
+	Summary ModulesSummary `json:"summary"`
+}
+
+// ModuleReport is the JSON report of one module in a multi-module run
+type ModuleReport struct {
+	Path   string          `json:"path"`
+	Failed bool            `json:"failed,omitempty"`
+	Report json.RawMessage `json:"report,omitempty"`
+}
+
+// ModulesSummary combines the statistics of every module in a multi-module run
+type ModulesSummary struct {
+	Modules               int `json:"modules"`
+	ModulesFailed         int `json:"modules_failed"`
+	VulnerabilitiesFixed  int `json:"vulnerabilities_fixed"`
+	VulnerabilitiesFailed int `json:"vulnerabilities_failed"`
+	PackagesUpdated       int `json:"packages_updated"`
+	PackagesFailed        int `json:"packages_failed"`
+	PackagesSkipped       int `json:"packages_skipped,omitempty"`
+}
+
+// summarizeModules adds up the statistics of every module
+func summarizeModules(modules []ModuleOutput) ModulesSummary {
+	summary := ModulesSummary{Modules: len(modules)}
+	for _, m := range modules {
+		if m.Failed {
+			summary.ModulesFailed++
+		}
+		summary.VulnerabilitiesFixed += m.Stats.VulnerabilitiesFixed
+		summary.VulnerabilitiesFailed += m.Stats.VulnerabilitiesFailed
+		summary.PackagesUpdated += m.Stats.PackagesUpdated
+		summary.PackagesFailed += m.Stats.PackagesFailed
+		summary.PackagesSkipped += m.Stats.PackagesSkipped
+	}
+	return summary
+}
+
+// moduleReports returns the JSON report of each module
+func moduleReports(modules []ModuleOutput) []ModuleReport {
+	reports := make([]ModuleReport, 0, len(modules))
+	for _, m := range modules {
+		report := ModuleReport{Path: m.Path, Failed: m.Failed}
+		if len(m.Output) > 0 {
+			report.Report = json.RawMessage(m.Output)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// ReportModuleList writes the reports of an explicit list of modules. In JSON it is an array
+// with an entry per module in list order and no combined summary; other formats are written
+// like ReportModules.
+func ReportModuleList(w io.Writer, format string, modules []ModuleOutput) error {
+	if format == "json" {
+		return writeJSON(w, moduleReports(modules))
+	}
+	return ReportModules(w, format, modules)
+}
+
+// ReportModules writes the reports of a multi-module run: a section per module followed by
+// a combined summary in text, a single document in JSON, and the concatenated lines in tuples.
+func ReportModules(w io.Writer, format string, modules []ModuleOutput) error {
+	summary := summarizeModules(modules)
+
+	switch format {
+	case "json":
+		return writeJSON(w, ModulesReport{Modules: moduleReports(modules), Summary: summary})
+	case "tuples":
+		for _, m := range modules {
+			if _, err := w.Write(m.Output); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "text":
+		for _, m := range modules {
+			fmt.Fprintf(w, "=== Module %s ===\n", m.Path)
+			if m.Failed {
+				fmt.Fprintln(w, "Failed to scan or patch this module, see the log for details.")
+			} else if len(m.Output) == 0 {
+				fmt.Fprintln(w, "No fixable vulnerabilities found.")
+			}
+			if _, err := w.Write(m.Output); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "Combined summary: %d module(s) scanned", summary.Modules)
+		if summary.ModulesFailed > 0 {
+			fmt.Fprintf(w, " (%d failed)", summary.ModulesFailed)
+		}
+		fmt.Fprintf(w, ", updated %d package(s) to fix %d vulnerabilities", summary.PackagesUpdated, summary.VulnerabilitiesFixed)
+		if summary.PackagesFailed > 0 {
+			fmt.Fprintf(w, ", %d package(s) failed (%d vulnerabilities not fixed)", summary.PackagesFailed, summary.VulnerabilitiesFailed)
+		}
+		if summary.PackagesSkipped > 0 {
+			fmt.Fprintf(w, ", %d package(s) skipped", summary.PackagesSkipped)
+		}
+		fmt.Fprintln(w)
+		return nil
+	default:
+		return fmt.Errorf("format %s does not support multiple modules", format)
+	}
+}
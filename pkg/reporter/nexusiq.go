@@ -0,0 +1,146 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// Nexus IQ threat levels range from 0 (none) to 10 (critical). Grump maps grype severities onto
+// the bands IQ's default security policies use: Critical 10, High 8, Medium 5, Low 2.
+var nexusThreatLevels = map[int]int{
+	5: 10, // Critical
+	4: 8,  // High
+	3: 5,  // Medium
+	2: 2,  // Low
+	1: 1,  // Negligible
+}
+
+// nexusReport is a Nexus IQ compatible policy report
+type nexusReport struct {
+	Components []nexusComponent `json:"components"`
+}
+
+// nexusComponent is a single module version with its policy violations
+type nexusComponent struct {
+	PackageURL          string              `json:"packageUrl"`
+	ComponentIdentifier nexusIdentifier     `json:"componentIdentifier"`
+	Violations          []nexusViolation    `json:"policyViolations"`
+	Metadata            map[string]string   `json:"metadata,omitempty"`
+	SecurityData        nexusSecurityIssues `json:"securityData"`
+}
+
+// nexusIdentifier identifies a component by format and coordinates
+type nexusIdentifier struct {
+	Format      string            `json:"format"`
+	Coordinates map[string]string `json:"coordinates"`
+}
+
+// nexusViolation is a security policy violation raised by a single advisory
+type nexusViolation struct {
+	PolicyName     string `json:"policyName"`
+	ThreatLevel    int    `json:"threatLevel"`
+	ConstraintName string `json:"constraintName"`
+	Status         string `json:"status"`
+	FixVersion     string `json:"fixVersion,omitempty"`
+}
+
+// nexusSecurityIssues lists the advisories affecting a component
+type nexusSecurityIssues struct {
+	SecurityIssues []nexusSecurityIssue `json:"securityIssues"`
+}
+
+// nexusSecurityIssue is a single advisory affecting a component
+type nexusSecurityIssue struct {
+	Reference   string `json:"reference"`
+	Severity    string `json:"severity"`
+	ThreatLevel int    `json:"threatLevel"`
+	Source      string `json:"source"`
+}
+
+// nexusThreatLevel maps a grype severity label to a Nexus IQ threat level
+func nexusThreatLevel(severity string) int {
+	return nexusThreatLevels[scanner.SeverityRank(severity)]
+}
+
+// nexusPolicyName names the IQ security policy violated at a given severity
+func nexusPolicyName(severity string) string {
+	if scanner.SeverityRank(severity) == 0 {
+		return "Security-Unknown"
+	}
+	return "Security-" + strings.ToUpper(severity[:1]) + strings.ToLower(severity[1:])
+}
+
+// reportNexusIQ outputs results as a Nexus IQ compatible policy report.
+// Each module version is a component; each advisory is a policy violation whose status is
+// "fixed" when grump patched the module and "open" otherwise.
+func (r *Reporter) reportNexusIQ(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	components := make(map[string]*nexusComponent)
+	var order []string
+
+	addViolation := func(name, version, vulnID, severity, status, fixVersion string) error {
+		if name == "" || version == "" || vulnID == "" {
+			return fmt.Errorf("finding is missing required fields (package %q, version %q, vulnerability %q)", name, version, vulnID)
+		}
+
+		purl := GoPURL(name, version)
+		c, ok := components[purl]
+		if !ok {
+			c = &nexusComponent{
+				PackageURL: purl,
+				ComponentIdentifier: nexusIdentifier{
+					Format:      "golang",
+					Coordinates: map[string]string{"name": name, "version": version},
+				},
+				Metadata: r.Metadata,
+			}
+			components[purl] = c
+			order = append(order, purl)
+		}
+
+		threatLevel := nexusThreatLevel(severity)
+		c.Violations = append(c.Violations, nexusViolation{
+			PolicyName:     nexusPolicyName(severity),
+			ThreatLevel:    threatLevel,
+			ConstraintName: vulnID,
+			Status:         status,
+			FixVersion:     fixVersion,
+		})
+		c.SecurityData.SecurityIssues = append(c.SecurityData.SecurityIssues, nexusSecurityIssue{
+			Reference:   vulnID,
+			Severity:    severity,
+			ThreatLevel: threatLevel,
+			Source:      "grype",
+		})
+		return nil
+	}
+
+	for _, update := range updates {
+		status := "open"
+		if s, _ := packageStatus(update.Name, results); s == StatusFixed {
+			status = "fixed"
+		}
+		if err := addViolation(update.Name, update.CurrentVersion, update.VulnID, update.SeverityForGating(), status, update.TargetVersion); err != nil {
+			return err
+		}
+	}
+	for _, vuln := range r.Unfixable {
+		if err := addViolation(vuln.Name, vuln.Version, vuln.VulnID, vuln.Severity, "open", ""); err != nil {
+			return err
+		}
+	}
+
+	sort.Strings(order)
+	report := nexusReport{Components: make([]nexusComponent, 0, len(order))}
+	for _, purl := range order {
+		report.Components = append(report.Components, *components[purl])
+	}
+
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
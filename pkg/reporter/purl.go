@@ -0,0 +1,21 @@
+package reporter
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GoPURL builds a package URL for a Go module, e.g. pkg:golang/github.com/ulikunitz/xz@v0.5.12.
+// Each path segment is escaped so that module paths map onto PURL namespace and name.
+func GoPURL(modulePath, version string) string {
+	segments := strings.Split(modulePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	purl := "pkg:golang/" + strings.Join(segments, "/")
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl
+}
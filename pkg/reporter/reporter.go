@@ -4,27 +4,136 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/divolgin/grump/pkg/patcher"
 	"github.com/divolgin/grump/pkg/scanner"
+	"github.com/divolgin/grump/pkg/state"
 )
 
 // Report contains the summary of the scan and fix operation
 type Report struct {
-	TotalVulnerabilities  int            `json:"total_vulnerabilities"`
-	VulnerabilitiesFixed  int            `json:"vulnerabilities_fixed"`
-	VulnerabilitiesFailed int            `json:"vulnerabilities_failed"`
-	PackagesUpdated       int            `json:"packages_updated"`
-	PackagesFailed        int            `json:"packages_failed"`
-	Updates               []UpdateReport `json:"updates"`
+	DryRun                bool               `json:"dry_run,omitempty"`
+	ReportOnly            bool               `json:"report_only,omitempty"`
+	RemoteURL             string             `json:"remote_url,omitempty"`
+	BuildError            string             `json:"build_error,omitempty"`
+	TotalVulnerabilities  int                `json:"total_vulnerabilities"`
+	VulnerabilitiesFixed  int                `json:"vulnerabilities_fixed"`
+	VulnerabilitiesFailed int                `json:"vulnerabilities_failed"`
+	PackagesUpdated       int                `json:"packages_updated"`
+	PackagesFailed        int                `json:"packages_failed"`
+	PackagesSkipped       int                `json:"packages_skipped,omitempty"`
+	PackagesRolledBack    int                `json:"packages_rolled_back,omitempty"`
+	Updates               []UpdateReport     `json:"updates"`
+	TidyMessages          []TidyReport       `json:"tidy_messages,omitempty"`
+	MainModuleFindings    []UpdateReport     `json:"main_module_findings,omitempty"`
+	Metadata              map[string]string  `json:"metadata,omitempty"`
+	Advisories            []AdvisoryReport   `json:"advisories,omitempty"`
+	GoSumCreated          bool               `json:"gosum_created,omitempty"`
+	TidySkipped           bool               `json:"tidy_skipped,omitempty"`
+	ModuleFiles           *ModuleFiles       `json:"module_files,omitempty"`
+	GoVersionResolutions  []ResolutionReport `json:"go_version_resolutions,omitempty"`
+	Changes               *state.Delta       `json:"changes,omitempty"`
+	Unfixable             []UnfixableReport  `json:"unfixable,omitempty"`
+	OSVulnerabilities     []OSReport         `json:"os_vulnerabilities,omitempty"`
+	BaselineDiff          *ReportDiff        `json:"baseline_diff,omitempty"`
+	Timings               *Timings           `json:"timings,omitempty"`
+	FixChains             []FixChain         `json:"fix_chains,omitempty"`
+	Diff                  string             `json:"diff,omitempty"`
+
+	// The inputs the report was built from, used to render it in other formats
+	reporter *Reporter
+	updates  []scanner.PackageUpdate
+	results  []patcher.UpdateResult
+}
+
+// Write renders the report to w in the given output format
+func (rep *Report) Write(w io.Writer, format string) error {
+	if rep.reporter == nil {
+		return fmt.Errorf("report was not built by a Reporter and can't be rendered")
+	}
+	r := *rep.reporter
+	r.writer = w
+	return r.ReportResults(rep.updates, rep.results, format)
+}
+
+// Results returns the update results the report was built from
+func (rep *Report) Results() []patcher.UpdateResult {
+	return rep.results
+}
+
+// Stats returns the update statistics of the report
+func (rep *Report) Stats() ResultStats {
+	return AnalyzeResults(rep.updates, rep.results)
+}
+
+// HasFindings reports whether there is anything to report: fixable or unfixable
+// vulnerabilities, or changes since the previous run
+func (rep *Report) HasFindings() bool {
+	return rep.TotalVulnerabilities > 0 || len(rep.Unfixable) > 0 || len(rep.OSVulnerabilities) > 0 ||
+		(rep.Changes != nil && !rep.Changes.Empty()) ||
+		(rep.BaselineDiff != nil && !rep.BaselineDiff.Empty())
+}
+
+// UnfixableReport is a vulnerability without an available fix
+type UnfixableReport struct {
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	VulnID   string `json:"vulnerability_id"`
+	Severity string `json:"severity"`
+	FixState string `json:"fix_state"`
+}
+
+// OSReport is a vulnerability in a package that isn't a Go module, which grump can't patch
+type OSReport struct {
+	Package     string   `json:"package"`
+	Version     string   `json:"version"`
+	Type        string   `json:"type"`
+	VulnID      string   `json:"vulnerability_id"`
+	Severity    string   `json:"severity"`
+	FixState    string   `json:"fix_state"`
+	FixVersions []string `json:"fix_versions,omitempty"`
+}
+
+// ResolutionReport is the outcome of resolving one update under one Go version
+type ResolutionReport struct {
+	GoVersion  string   `json:"go_version"`
+	Package    string   `json:"package,omitempty"`
+	Target     string   `json:"target_version,omitempty"`
+	Resolved   string   `json:"resolved_version,omitempty"`
+	Effective  bool     `json:"effective"`
+	Downgrades []string `json:"downgrades,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ModuleFiles holds the go.mod and go.sum contents before and after patching
+type ModuleFiles struct {
+	Before ModuleFileContents `json:"before"`
+	After  ModuleFileContents `json:"after"`
+}
+
+// ModuleFileContents holds raw go.mod and go.sum contents
+type ModuleFileContents struct {
+	GoMod string `json:"go_mod"`
+	GoSum string `json:"go_sum,omitempty"`
+}
+
+// TidyReport contains a single classified go mod tidy message
+type TidyReport struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
 }
 
 // ResultStats contains statistics about the update results
 type ResultStats struct {
-	PackagesUpdated       int
-	PackagesFailed        int
-	VulnerabilitiesFixed  int
-	VulnerabilitiesFailed int
+	PackagesUpdated        int
+	PackagesFailed         int
+	PackagesSkipped        int
+	PackagesRolledBack     int
+	VulnerabilitiesFixed   int
+	VulnerabilitiesFailed  int
+	VulnerabilitiesSkipped int
 }
 
 // AnalyzeResults analyzes update results and returns statistics
@@ -34,11 +143,17 @@ func AnalyzeResults(updates []scanner.PackageUpdate, results []patcher.UpdateRes
 	// Build a map of successfully updated packages
 	updatedPackages := make(map[string]bool)
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			stats.PackagesUpdated++
 			updatedPackages[result.Update.Name] = true
-		} else {
+		case result.Skipped:
+			stats.PackagesSkipped++
+		default:
 			stats.PackagesFailed++
+			if result.RolledBack {
+				stats.PackagesRolledBack++
+			}
 		}
 	}
 
@@ -47,16 +162,22 @@ func AnalyzeResults(updates []scanner.PackageUpdate, results []patcher.UpdateRes
 		if updatedPackages[update.Name] {
 			stats.VulnerabilitiesFixed++
 		} else {
-			// Check if this package had any failed updates
-			hasFailed := false
+			// Check if this package had any failed or intentionally skipped updates
+			hasFailed, hasSkipped := false, false
 			for _, result := range results {
-				if result.Update.Name == update.Name && !result.Success {
+				if result.Update.Name != update.Name || result.Success {
+					continue
+				}
+				if result.Skipped {
+					hasSkipped = true
+				} else {
 					hasFailed = true
-					break
 				}
 			}
 			if hasFailed {
 				stats.VulnerabilitiesFailed++
+			} else if hasSkipped {
+				stats.VulnerabilitiesSkipped++
 			}
 		}
 	}
@@ -66,59 +187,327 @@ func AnalyzeResults(updates []scanner.PackageUpdate, results []patcher.UpdateRes
 
 // UpdateReport contains details about a single update
 type UpdateReport struct {
-	Package        string `json:"package"`
-	CurrentVersion string `json:"current_version"`
-	TargetVersion  string `json:"target_version"`
-	VulnID         string `json:"vulnerability_id"`
-	Severity       string `json:"severity"`
-	Success        bool   `json:"success"`
-	Error          string `json:"error,omitempty"`
+	Package           string   `json:"package"`
+	CurrentVersion    string   `json:"current_version"`
+	TargetVersion     string   `json:"target_version"`
+	VulnID            string   `json:"vulnerability_id"`
+	VulnIDs           []string `json:"vulnerability_ids,omitempty"`
+	Severity          string   `json:"severity"`
+	EffectiveSeverity string   `json:"effective_severity"`
+	KnownExploited    bool     `json:"known_exploited,omitempty"`
+	Confidence        string   `json:"confidence,omitempty"`
+	Direct            bool     `json:"direct"`
+	Success           bool     `json:"success"`
+	Error             string   `json:"error,omitempty"`
+	ModulePath        string   `json:"module_path,omitempty"`
+	Skipped           bool     `json:"skipped,omitempty"`
+	Reason            string   `json:"reason,omitempty"`
+	RolledBack        bool     `json:"rolled_back,omitempty"`
+	LatestAvailable   string   `json:"latest_available,omitempty"`
+	Mechanism         string   `json:"mechanism,omitempty"`
+	EPSS              float64  `json:"epss,omitempty"`
+	// Explanation is included with Reporter.Explain
+	Explanation *ExplanationReport `json:"explanation,omitempty"`
 }
 
 // Reporter handles output formatting
 type Reporter struct {
 	writer io.Writer
+
+	// Verbose includes diagnostic details, such as go mod tidy messages, in the report
+	Verbose bool
+	// TidyMessages are the classified go mod tidy messages from the patch run
+	TidyMessages []patcher.TidyMessage
+	// MainModuleUpdates are advisories matching the scanned module itself, which are never patched
+	MainModuleUpdates []scanner.PackageUpdate
+	// Metadata is arbitrary key/value data, such as CI build details, attached to the report
+	Metadata map[string]string
+	// Unfixable are Go module vulnerabilities without an available fix
+	Unfixable []scanner.UnfixableVulnerability
+	// OSVulnerabilities are findings in packages that aren't Go modules, reported only
+	OSVulnerabilities []scanner.OSVulnerability
+	// Resolutions are dry-run outcomes of the updates under other Go toolchain versions
+	Resolutions []patcher.GoVersionResolution
+	// FixChains are the modules patched more than once because a fix version was vulnerable
+	FixChains []FixChain
+	// ModuleFiles embeds go.mod and go.sum before and after patching in JSON output only
+	ModuleFiles *ModuleFiles
+	// Diff is a unified diff of the changes patching made to go.mod and go.sum
+	Diff string
+	// GoSumCreated is set when patching created a go.sum file that did not exist before
+	GoSumCreated bool
+	// TidySkipped is set when go mod tidy was not run after patching
+	TidySkipped bool
+	// View selects the grouping of text and JSON output: ViewPackage (default) or ViewAdvisory
+	View string
+	// Changes are the findings that changed since the previous run, when a state file is used
+	Changes *state.Delta
+	// Baseline is an earlier report to compare the findings against, see Diff
+	Baseline *Report
+	// BaselinePath is where Baseline was loaded from, for display
+	BaselinePath string
+	// DryRun labels the report as describing updates that were not actually applied
+	DryRun bool
+	// ReportOnly labels the report as a scan of something that can't be patched, such as a
+	// compiled binary; the updates are listed as recommendations only
+	ReportOnly bool
+	// RemoteURL is the git repository a report-only scan was cloned from
+	RemoteURL string
+	// BuildError is the compile failure of the patched project, when build verification is enabled
+	BuildError error
+	// Version is the grump version named in formats that identify the tool, such as SARIF
+	Version string
+	// Timings are how long the stages of the run took, shown as a footer of the text report
+	Timings *Timings
+	// Template is the text/template rendered by the template format, see ReportResultsTo
+	Template string
+	// SummaryOnly reduces the text report to its summary line, see Summary
+	SummaryOnly bool
+	// Explain includes the rationale for each update's target version in text and JSON output
+	Explain bool
+	// SortBy orders the updates: SortSeverity (default) or SortEPSS
+	SortBy string
 }
 
+// Formats lists the output formats supported by ReportResults
+var Formats = []string{"text", "json", "actions", "nexus-iq", "tuples", "sarif", "junit", "markdown", "vex", "csv", "html", "template"}
+
+// IsValidFormat reports whether format is a supported output format
+func IsValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Report views
+const (
+	ViewPackage  = "package"
+	ViewAdvisory = "advisory"
+)
+
+// Update orders, see Reporter.SortBy
+const (
+	SortSeverity = "severity"
+	SortEPSS     = "epss"
+)
+
 // New creates a new Reporter instance
 func New(writer io.Writer) *Reporter {
 	return &Reporter{writer: writer}
 }
 
+// toolVersion returns the reported grump version, "dev" when unset
+func (r *Reporter) toolVersion() string {
+	if r.Version == "" {
+		return "dev"
+	}
+	return r.Version
+}
+
+// updateLess orders updates from most to least severe (Critical, High, Medium, Low, Negligible,
+// then Unknown), alphabetically by package within a severity, and by vulnerability within a
+// package, so that reports of identical scans are identical
+func updateLess(a, b scanner.PackageUpdate) bool {
+	rankA, rankB := scanner.SeverityRank(a.SeverityForGating()), scanner.SeverityRank(b.SeverityForGating())
+	if rankA != rankB {
+		return rankA > rankB
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.VulnID < b.VulnID
+}
+
+// epssLess orders updates from most to least likely to be exploited, then like updateLess.
+// Updates without an EPSS score come last.
+func epssLess(a, b scanner.PackageUpdate) bool {
+	if a.EPSS != b.EPSS {
+		return a.EPSS > b.EPSS
+	}
+	return updateLess(a, b)
+}
+
+// less returns the update order selected by SortBy
+func (r *Reporter) less() func(a, b scanner.PackageUpdate) bool {
+	if r.SortBy == SortEPSS {
+		return epssLess
+	}
+	return updateLess
+}
+
+// sortUpdates returns the updates in report order, see SortBy
+func (r *Reporter) sortUpdates(updates []scanner.PackageUpdate) []scanner.PackageUpdate {
+	less := r.less()
+	sorted := append([]scanner.PackageUpdate(nil), updates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// sortResults returns the update results in report order, see SortBy
+func (r *Reporter) sortResults(results []patcher.UpdateResult) []patcher.UpdateResult {
+	less := r.less()
+	sorted := append([]patcher.UpdateResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i].Update, sorted[j].Update)
+	})
+	return sorted
+}
+
 // ReportResults outputs the results of the scan and update operation
 func (r *Reporter) ReportResults(updates []scanner.PackageUpdate, results []patcher.UpdateResult, format string) error {
-	if format == "json" {
+	updates, results = r.sortUpdates(updates), r.sortResults(results)
+
+	switch format {
+	case "json":
 		return r.reportJSON(updates, results)
+	case "actions":
+		return r.reportActions(updates, results)
+	case "nexus-iq":
+		return r.reportNexusIQ(updates, results)
+	case "tuples":
+		return r.reportTuples(updates, results)
+	case "sarif":
+		return r.reportSARIF(updates, results)
+	case "junit":
+		return r.reportJUnit(updates, results)
+	case "markdown":
+		return r.reportMarkdown(updates, results)
+	case "vex":
+		return r.reportVEX(updates, results)
+	case "csv":
+		return r.reportCSV(updates, results)
+	case "html":
+		return r.reportHTML(updates, results)
+	case "template":
+		return r.reportTemplate(updates, results)
+	default:
+		if r.SummaryOnly {
+			return r.reportSummaryOnly(updates, results)
+		}
+		if r.View == ViewAdvisory {
+			return r.reportAdvisoryText(updates, results)
+		}
+		return r.reportText(updates, results)
 	}
-	return r.reportText(updates, results)
 }
 
 // reportText outputs results in human-readable text format
 func (r *Reporter) reportText(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
 	if len(updates) == 0 {
 		fmt.Fprintln(r.writer, "No fixable vulnerabilities found.")
+		if len(r.Unfixable) > 0 {
+			r.reportUnfixableText()
+		}
+		if len(r.OSVulnerabilities) > 0 {
+			r.reportOSText()
+		}
+		if r.Changes != nil {
+			r.reportChangesText()
+		}
+		if r.Baseline != nil {
+			r.reportBaselineText(r.BuildReport(updates, results).BaselineDiff)
+		}
+		if r.Timings != nil {
+			r.reportTimingsText()
+		}
 		return nil
 	}
 
 	fmt.Fprintf(r.writer, "Found %d fixable vulnerabilities:\n", len(updates))
 	for _, update := range updates {
-		fmt.Fprintf(r.writer, "  - %s %s → %s (%s, %s)\n",
+		fmt.Fprintf(r.writer, "  - %s %s → %s (%s, %s)",
 			update.Name,
 			update.CurrentVersion,
 			update.TargetVersion,
 			update.VulnID,
-			update.Severity,
+			formatSeverity(update),
 		)
+		if !update.Direct {
+			fmt.Fprint(r.writer, " [indirect]")
+		}
+		if update.Confidence == scanner.ConfidenceLow {
+			fmt.Fprint(r.writer, " [low confidence]")
+		}
+		if update.LatestAvailable != "" {
+			fmt.Fprintf(r.writer, " [%s available]", update.LatestAvailable)
+		}
+		fmt.Fprintln(r.writer)
+		if r.Explain {
+			r.reportExplanationText(update)
+		}
+	}
+
+	if r.ReportOnly {
+		if r.RemoteURL != "" {
+			fmt.Fprintf(r.writer, "\nReport only: %s was scanned from a temporary clone. Apply these updates in a local checkout.\n", r.RemoteURL)
+		} else {
+			fmt.Fprintln(r.writer, "\nReport only: compiled binaries can't be patched. Update these modules in the source project and rebuild.")
+		}
+		if len(r.Unfixable) > 0 {
+			r.reportUnfixableText()
+		}
+		if len(r.OSVulnerabilities) > 0 {
+			r.reportOSText()
+		}
+		if r.Changes != nil {
+			r.reportChangesText()
+		}
+		if r.Baseline != nil {
+			r.reportBaselineText(r.BuildReport(updates, results).BaselineDiff)
+		}
+		return nil
 	}
 
-	fmt.Fprintln(r.writer, "\nUpdating dependencies...")
+	if r.DryRun {
+		fmt.Fprintln(r.writer, "\nDry run: go.mod and go.sum were not modified.")
+	} else {
+		fmt.Fprintln(r.writer, "\nUpdating dependencies...")
+	}
 
 	for _, result := range results {
-		if result.Success {
-			fmt.Fprintf(r.writer, "  ✓ Updated %s to %s\n",
+		if result.Skipped {
+			fmt.Fprintf(r.writer, "  - Skipped %s: %s\n",
+				result.Update.Name,
+				result.Reason,
+			)
+		} else if result.RolledBack {
+			fmt.Fprintf(r.writer, "  ↺ Rolled back %s: update broke the build\n",
+				result.Update.Name,
+			)
+		} else if result.Success && result.Mechanism == patcher.MechanismAlreadySatisfied {
+			fmt.Fprintf(r.writer, "  ✓ Already satisfied %s: go.mod requires %s or newer\n",
 				result.Update.Name,
 				result.Update.TargetVersion,
 			)
+		} else if result.Success && r.DryRun {
+			fmt.Fprintf(r.writer, "  ~ Would update %s to %s",
+				result.Update.Name,
+				result.Update.TargetVersion,
+			)
+			if n := len(result.Update.VulnIDs); n > 1 {
+				fmt.Fprintf(r.writer, " (fixes %d vulnerabilities)", n)
+			}
+			fmt.Fprintln(r.writer)
+		} else if result.Success {
+			fmt.Fprintf(r.writer, "  ✓ Updated %s to %s",
+				result.Update.Name,
+				result.Update.TargetVersion,
+			)
+			if result.ModulePath != "" {
+				fmt.Fprintf(r.writer, " (required as %s)", result.ModulePath)
+			}
+			if result.Mechanism == patcher.MechanismGoGet {
+				fmt.Fprint(r.writer, " (via go get)")
+			}
+			if n := len(result.Update.VulnIDs); n > 1 {
+				fmt.Fprintf(r.writer, " (fixes %d vulnerabilities)", n)
+			}
+			fmt.Fprintln(r.writer)
 		} else {
 			fmt.Fprintf(r.writer, "  ✗ Failed to update %s: %v\n",
 				result.Update.Name,
@@ -127,50 +516,379 @@ func (r *Reporter) reportText(updates []scanner.PackageUpdate, results []patcher
 		}
 	}
 
-	// Analyze results to get statistics
-	stats := AnalyzeResults(updates, results)
+	fmt.Fprintln(r.writer)
+	r.reportSummaryText(r.Summary(updates, results))
+	fmt.Fprintln(r.writer)
+
+	if r.GoSumCreated {
+		fmt.Fprintln(r.writer, "Note: go.sum did not exist and was created.")
+	}
+	if r.TidySkipped {
+		fmt.Fprintln(r.writer, "Note: go mod tidy was skipped; run it before committing if go.sum needs updating.")
+	}
+
+	if r.BuildError != nil {
+		fmt.Fprintln(r.writer, "\nThe project no longer builds after patching:")
+		for _, line := range strings.Split(r.BuildError.Error(), "\n") {
+			fmt.Fprintf(r.writer, "  %s\n", line)
+		}
+	}
+
+	if len(r.Unfixable) > 0 {
+		r.reportUnfixableText()
+	}
 
-	fmt.Fprintf(r.writer, "\nSummary: Updated %d package(s) to fix %d vulnerabilities", stats.PackagesUpdated, stats.VulnerabilitiesFixed)
+	if len(r.OSVulnerabilities) > 0 {
+		r.reportOSText()
+	}
+
+	if r.Changes != nil {
+		r.reportChangesText()
+	}
+
+	if r.Baseline != nil {
+		r.reportBaselineText(r.BuildReport(updates, results).BaselineDiff)
+	}
+
+	if len(r.Resolutions) > 0 {
+		r.reportResolutionsText()
+	}
+
+	if len(r.FixChains) > 0 {
+		r.reportFixChainsText()
+	}
+
+	if len(r.MainModuleUpdates) > 0 {
+		fmt.Fprintf(r.writer, "\nSkipped %d advisories matching the scanned module itself:\n", len(r.MainModuleUpdates))
+		for _, update := range r.MainModuleUpdates {
+			fmt.Fprintf(r.writer, "  - %s %s (%s, %s)\n",
+				update.Name,
+				update.CurrentVersion,
+				update.VulnID,
+				update.Severity,
+			)
+		}
+	}
+
+	if r.Verbose && len(r.Metadata) > 0 {
+		fmt.Fprintln(r.writer, "\nMetadata:")
+		keys := make([]string, 0, len(r.Metadata))
+		for key := range r.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(r.writer, "  %s=%s\n", key, r.Metadata[key])
+		}
+	}
+
+	if r.Verbose && len(r.TidyMessages) > 0 {
+		fmt.Fprintln(r.writer, "\ngo mod tidy output:")
+		for _, msg := range r.TidyMessages {
+			fmt.Fprintf(r.writer, "  [%s] %s\n", msg.Level, msg.Message)
+		}
+	}
+
+	if r.Diff != "" {
+		fmt.Fprintln(r.writer, "\nChanges to go.mod and go.sum:")
+		fmt.Fprint(r.writer, r.Diff)
+	}
+
+	if r.Timings != nil {
+		r.reportTimingsText()
+	}
+
+	return nil
+}
+
+// Summary returns the headline counts of the update results without rendering anything
+func (r *Reporter) Summary(updates []scanner.PackageUpdate, results []patcher.UpdateResult) ResultStats {
+	return AnalyzeResults(updates, results)
+}
+
+// reportSummaryText writes the summary line of the text report, without a trailing newline
+func (r *Reporter) reportSummaryText(stats ResultStats) {
+	if r.DryRun {
+		fmt.Fprintf(r.writer, "Summary (dry run): Would update %d package(s) to fix %d vulnerabilities", stats.PackagesUpdated, stats.VulnerabilitiesFixed)
+	} else {
+		fmt.Fprintf(r.writer, "Summary: Updated %d package(s) to fix %d vulnerabilities", stats.PackagesUpdated, stats.VulnerabilitiesFixed)
+	}
 	if stats.PackagesFailed > 0 {
 		fmt.Fprintf(r.writer, ", %d package(s) failed (%d vulnerabilities not fixed)", stats.PackagesFailed, stats.VulnerabilitiesFailed)
 	}
-	fmt.Fprintln(r.writer)
+	if stats.PackagesRolledBack > 0 {
+		fmt.Fprintf(r.writer, ", %d package(s) rolled back after breaking the build", stats.PackagesRolledBack)
+	}
+	if stats.PackagesSkipped > 0 {
+		fmt.Fprintf(r.writer, ", %d package(s) skipped (%d vulnerabilities)", stats.PackagesSkipped, stats.VulnerabilitiesSkipped)
+	}
+}
 
+// reportSummaryOnly writes the summary line alone, also counting the vulnerabilities without a fix
+func (r *Reporter) reportSummaryOnly(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	r.reportSummaryText(r.Summary(updates, results))
+	if len(r.Unfixable) > 0 {
+		fmt.Fprintf(r.writer, ", %d vulnerabilities with no fix available", len(r.Unfixable))
+	}
+	fmt.Fprintln(r.writer)
 	return nil
 }
 
+// reportUnfixableText lists the vulnerabilities that have no fix and need manual attention
+func (r *Reporter) reportUnfixableText() {
+	fmt.Fprintf(r.writer, "\n%d vulnerabilities have no fix available and can't be patched (not actionable):\n", len(r.Unfixable))
+	for _, vuln := range r.Unfixable {
+		fmt.Fprintf(r.writer, "  - %s %s (%s, %s, %s)\n",
+			vuln.Name,
+			vuln.Version,
+			vuln.VulnID,
+			vuln.Severity,
+			vuln.FixState,
+		)
+	}
+}
+
+// reportOSText lists the vulnerabilities in packages grump can't patch because they aren't Go modules
+func (r *Reporter) reportOSText() {
+	fmt.Fprintf(r.writer, "\n%d vulnerabilities are in OS and other non-Go packages, which grump can't patch:\n", len(r.OSVulnerabilities))
+	for _, vuln := range r.OSVulnerabilities {
+		fmt.Fprintf(r.writer, "  - %s %s [%s] (%s, %s, %s",
+			vuln.Name,
+			vuln.Version,
+			vuln.Type,
+			vuln.VulnID,
+			vuln.Severity,
+			vuln.FixState,
+		)
+		if len(vuln.FixVersions) > 0 {
+			fmt.Fprintf(r.writer, ", fixed in %s", strings.Join(vuln.FixVersions, ", "))
+		}
+		fmt.Fprintln(r.writer, ")")
+	}
+}
+
+// reportChangesText lists the findings that changed since the previous run
+func (r *Reporter) reportChangesText() {
+	if r.Changes.Reset {
+		fmt.Fprintf(r.writer, "\nNo previous state; recorded %d finding(s) as the new baseline.\n", len(r.Changes.New))
+		return
+	}
+	if r.Changes.Empty() {
+		fmt.Fprintln(r.writer, "\nNo changes since the last run.")
+		return
+	}
+
+	fmt.Fprintf(r.writer, "\nChanges since the last run: %d new, %d reappeared, %d fixed\n",
+		len(r.Changes.New), len(r.Changes.Reappeared), len(r.Changes.Fixed))
+	for _, group := range []struct {
+		marker   string
+		findings []state.Finding
+	}{
+		{"+", r.Changes.New},
+		{"!", r.Changes.Reappeared},
+		{"-", r.Changes.Fixed},
+	} {
+		for _, f := range group.findings {
+			fmt.Fprintf(r.writer, "  %s %s %s (%s)\n", group.marker, f.Package, f.Version, f.VulnID)
+		}
+	}
+}
+
+// reportResolutionsText lists updates that don't resolve cleanly under every requested Go version
+func (r *Reporter) reportResolutionsText() {
+	problems := 0
+	for _, res := range r.Resolutions {
+		if !res.Effective() {
+			problems++
+		}
+	}
+
+	if problems == 0 {
+		fmt.Fprintln(r.writer, "\nAll updates resolve as expected under every requested Go version.")
+		return
+	}
+
+	fmt.Fprintf(r.writer, "\n%d update resolution problem(s) across Go versions:\n", problems)
+	for _, res := range r.Resolutions {
+		if res.Effective() {
+			continue
+		}
+		switch {
+		case res.Error != nil && res.Package == "":
+			fmt.Fprintf(r.writer, "  ✗ Go %s: %v\n", res.GoVersion, res.Error)
+		case res.Error != nil:
+			fmt.Fprintf(r.writer, "  ✗ Go %s: %s → %s failed: %v\n", res.GoVersion, res.Package, res.Target, res.Error)
+		case len(res.Downgrades) > 0:
+			fmt.Fprintf(r.writer, "  ✗ Go %s: %s → %s downgrades %s\n", res.GoVersion, res.Package, res.Target, strings.Join(res.Downgrades, ", "))
+		default:
+			fmt.Fprintf(r.writer, "  ✗ Go %s: %s → %s resolved to %s\n", res.GoVersion, res.Package, res.Target, res.Resolved)
+		}
+	}
+}
+
+// formatSeverity renders an update's severity, noting escalation, known exploitation, and
+// the EPSS score when there is one
+func formatSeverity(update scanner.PackageUpdate) string {
+	severity := update.Severity
+	if effective := update.SeverityForGating(); effective != update.Severity {
+		severity = fmt.Sprintf("%s → %s", update.Severity, effective)
+	}
+	if update.KnownExploited {
+		severity += ", known exploited"
+	}
+	if update.EPSS > 0 {
+		severity += fmt.Sprintf(", EPSS %.1f%%", update.EPSS*100)
+	}
+	return severity
+}
+
 // reportJSON outputs results in JSON format
 func (r *Reporter) reportJSON(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	report := *r.BuildReport(updates, results)
+
+	// Tidy output is diagnostic and only included on request
+	if !r.Verbose {
+		report.TidyMessages = nil
+	}
+
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// BuildReport assembles the structured report of a run. It is what the JSON format encodes,
+// and it can be rendered in any other format with Report.Write.
+func (r *Reporter) BuildReport(updates []scanner.PackageUpdate, results []patcher.UpdateResult) *Report {
+	updates, results = r.sortUpdates(updates), r.sortResults(results)
+
 	// Analyze results to get statistics
 	stats := AnalyzeResults(updates, results)
 
-	report := Report{
+	config := *r
+	report := &Report{
+		reporter: &config,
+		updates:  updates,
+		results:  results,
+
+		DryRun:                r.DryRun,
+		ReportOnly:            r.ReportOnly,
+		RemoteURL:             r.RemoteURL,
 		TotalVulnerabilities:  len(updates),
 		VulnerabilitiesFixed:  stats.VulnerabilitiesFixed,
 		VulnerabilitiesFailed: stats.VulnerabilitiesFailed,
 		PackagesUpdated:       stats.PackagesUpdated,
 		PackagesFailed:        stats.PackagesFailed,
+		PackagesSkipped:       stats.PackagesSkipped,
+		PackagesRolledBack:    stats.PackagesRolledBack,
 		Updates:               make([]UpdateReport, 0, len(results)),
+		Metadata:              r.Metadata,
+		GoSumCreated:          r.GoSumCreated,
+		TidySkipped:           r.TidySkipped,
+		ModuleFiles:           r.ModuleFiles,
+		Diff:                  r.Diff,
+		Changes:               r.Changes,
+		Timings:               r.Timings,
+	}
+	if r.BuildError != nil {
+		report.BuildError = r.BuildError.Error()
 	}
 
 	for _, result := range results {
 		updateReport := UpdateReport{
-			Package:        result.Update.Name,
-			CurrentVersion: result.Update.CurrentVersion,
-			TargetVersion:  result.Update.TargetVersion,
-			VulnID:         result.Update.VulnID,
-			Severity:       result.Update.Severity,
-			Success:        result.Success,
+			Package:           result.Update.Name,
+			CurrentVersion:    result.Update.CurrentVersion,
+			TargetVersion:     result.Update.TargetVersion,
+			VulnID:            result.Update.VulnID,
+			VulnIDs:           result.Update.VulnIDs,
+			Severity:          result.Update.Severity,
+			EffectiveSeverity: result.Update.SeverityForGating(),
+			KnownExploited:    result.Update.KnownExploited,
+			Confidence:        result.Update.Confidence,
+			Direct:            result.Update.Direct,
+			Success:           result.Success,
+			ModulePath:        result.ModulePath,
+			Skipped:           result.Skipped,
+			Reason:            result.Reason,
+			RolledBack:        result.RolledBack,
+			LatestAvailable:   result.Update.LatestAvailable,
+			Mechanism:         result.Mechanism,
+			EPSS:              result.Update.EPSS,
 		}
 
 		if result.Error != nil {
 			updateReport.Error = result.Error.Error()
 		}
+		if r.Explain {
+			updateReport.Explanation = explainUpdate(result.Update)
+		}
 
 		report.Updates = append(report.Updates, updateReport)
 	}
 
-	encoder := json.NewEncoder(r.writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(report)
+	for _, vuln := range r.Unfixable {
+		report.Unfixable = append(report.Unfixable, UnfixableReport{
+			Package:  vuln.Name,
+			Version:  vuln.Version,
+			VulnID:   vuln.VulnID,
+			Severity: vuln.Severity,
+			FixState: vuln.FixState,
+		})
+	}
+
+	for _, vuln := range r.OSVulnerabilities {
+		report.OSVulnerabilities = append(report.OSVulnerabilities, OSReport{
+			Package:     vuln.Name,
+			Version:     vuln.Version,
+			Type:        vuln.Type,
+			VulnID:      vuln.VulnID,
+			Severity:    vuln.Severity,
+			FixState:    vuln.FixState,
+			FixVersions: vuln.FixVersions,
+		})
+	}
+
+	for _, res := range r.Resolutions {
+		resolution := ResolutionReport{
+			GoVersion:  res.GoVersion,
+			Package:    res.Package,
+			Target:     res.Target,
+			Resolved:   res.Resolved,
+			Effective:  res.Effective(),
+			Downgrades: res.Downgrades,
+		}
+		if res.Error != nil {
+			resolution.Error = res.Error.Error()
+		}
+		report.GoVersionResolutions = append(report.GoVersionResolutions, resolution)
+	}
+
+	if r.View == ViewAdvisory {
+		report.Advisories = buildAdvisories(updates, results, r.Unfixable)
+	}
+
+	for _, update := range r.MainModuleUpdates {
+		report.MainModuleFindings = append(report.MainModuleFindings, UpdateReport{
+			Package:           update.Name,
+			CurrentVersion:    update.CurrentVersion,
+			TargetVersion:     update.TargetVersion,
+			VulnID:            update.VulnID,
+			Severity:          update.Severity,
+			EffectiveSeverity: update.SeverityForGating(),
+			KnownExploited:    update.KnownExploited,
+		})
+	}
+
+	for _, msg := range r.TidyMessages {
+		report.TidyMessages = append(report.TidyMessages, TidyReport{
+			Level:   string(msg.Level),
+			Message: msg.Message,
+		})
+	}
+
+	if r.Baseline != nil {
+		report.BaselineDiff = Diff(r.Baseline, report)
+		report.BaselineDiff.Baseline = r.BaselinePath
+	}
+
+	return report
 }
@@ -0,0 +1,220 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// testFindings returns a fixed, a failed, and a skipped update, with their results, and an
+// unfixable vulnerability
+func testFindings() ([]scanner.PackageUpdate, []patcher.UpdateResult, []scanner.UnfixableVulnerability) {
+	updates := []scanner.PackageUpdate{
+		{Name: "example.com/fixed", CurrentVersion: "v1.0.0", TargetVersion: "v1.0.1", VulnID: "GHSA-fixed", Severity: "High", EffectiveSeverity: "High"},
+		{Name: "example.com/failed", CurrentVersion: "v2.0.0", TargetVersion: "v2.1.0", VulnID: "CVE-2024-2", Severity: "Medium", EffectiveSeverity: "Critical"},
+		{Name: "example.com/skipped", CurrentVersion: "v0.1.0", TargetVersion: "v0.2.0", VulnID: "GHSA-skipped", Severity: "Low", EffectiveSeverity: "Low"},
+	}
+	results := []patcher.UpdateResult{
+		{Update: updates[0], Success: true, Mechanism: patcher.MechanismGobump},
+		{Update: updates[1], Error: errors.New("failed to update: go: conflict,\nsee \"go.mod\"")},
+		{Update: updates[2], Skipped: true, Reason: "outside the patch policy"},
+	}
+	unfixable := []scanner.UnfixableVulnerability{
+		{Name: "example.com/nofix", Version: "v3.0.0", VulnID: "GHSA-nofix", Severity: "High", FixState: "not-fixed"},
+	}
+	return updates, results, unfixable
+}
+
+// render writes the test findings in format and returns the output
+func render(t *testing.T, format string) []byte {
+	t.Helper()
+	updates, results, unfixable := testFindings()
+	var buf bytes.Buffer
+	r := New(&buf)
+	r.Unfixable = unfixable
+	if err := r.ReportResults(updates, results, format); err != nil {
+		t.Fatalf("%s report: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestReportTuples(t *testing.T) {
+	got := string(render(t, "tuples"))
+	want := "example.com/failed\tv2.0.0->v2.1.0\tCVE-2024-2\tCritical\tfailed\n" +
+		"example.com/fixed\tv1.0.0->v1.0.1\tGHSA-fixed\tHigh\tfixed\n" +
+		"example.com/skipped\tv0.1.0->v0.2.0\tGHSA-skipped\tLow\tskipped\n" +
+		"example.com/nofix\tv3.0.0->-\tGHSA-nofix\tHigh\tno-fix\n"
+	if got != want {
+		t.Errorf("tuples report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	rows, err := csv.NewReader(bytes.NewReader(render(t, "csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV report doesn't parse: %v", err)
+	}
+	want := [][]string{
+		csvHeader,
+		{"example.com/failed", "v2.0.0", "v2.1.0", "CVE-2024-2", "Critical", "false", "failed to update: go: conflict,\nsee \"go.mod\""},
+		{"example.com/fixed", "v1.0.0", "v1.0.1", "GHSA-fixed", "High", "true", ""},
+		{"example.com/skipped", "v0.1.0", "v0.2.0", "GHSA-skipped", "Low", "false", "outside the patch policy"},
+		{"example.com/nofix", "v3.0.0", "", "GHSA-nofix", "High", "false", "no fix available"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %q", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestReportSARIF(t *testing.T) {
+	var log sarifLog
+	if err := json.Unmarshal(render(t, "sarif"), &log); err != nil {
+		t.Fatalf("SARIF report doesn't parse: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("version = %q with %d runs, want %s with 1 run", log.Version, len(log.Runs), sarifVersion)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 4 || len(run.Tool.Driver.Rules) != 4 {
+		t.Fatalf("got %d results and %d rules, want 4 of each", len(run.Results), len(run.Tool.Driver.Rules))
+	}
+
+	first := run.Results[0]
+	if first.RuleID != "CVE-2024-2" || first.Level != "error" {
+		t.Errorf("first result = %s at level %s, want CVE-2024-2 at error for its effective severity", first.RuleID, first.Level)
+	}
+	if loc := first.Locations[0]; loc.PhysicalLocation.ArtifactLocation.URI != "go.mod" ||
+		loc.LogicalLocations[0].FullyQualifiedName != "example.com/failed@v2.0.0" {
+		t.Errorf("location = %+v, want go.mod and example.com/failed@v2.0.0", loc)
+	}
+	if msg := run.Results[1].Message.Text; !strings.HasSuffix(msg, "(updated by grump)") {
+		t.Errorf("fixed result message = %q, want it marked as updated", msg)
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	var suite junitTestSuite
+	if err := xml.Unmarshal(render(t, "junit"), &suite); err != nil {
+		t.Fatalf("JUnit report doesn't parse: %v", err)
+	}
+	if suite.Tests != 4 || suite.Failures != 1 || suite.Skipped != 2 || len(suite.TestCases) != 4 {
+		t.Fatalf("tests = %d, failures = %d, skipped = %d; want 4, 1, 2", suite.Tests, suite.Failures, suite.Skipped)
+	}
+
+	failed := suite.TestCases[0]
+	if failed.ClassName != "example.com/failed" || failed.Name != "CVE-2024-2 (Critical)" || failed.Failure == nil {
+		t.Fatalf("first test case = %+v, want the failed CVE-2024-2", failed)
+	}
+	if failed.Failure.Message != "failed to update: go: conflict," || !strings.Contains(failed.Failure.Text, "see \"go.mod\"") {
+		t.Errorf("failure = %+v, want the first line as message and the full error as text", failed.Failure)
+	}
+	if fixed := suite.TestCases[1]; fixed.Failure != nil || fixed.Skipped != nil {
+		t.Errorf("fixed test case = %+v, want it to pass", fixed)
+	}
+	if nofix := suite.TestCases[3]; nofix.Skipped == nil || nofix.Skipped.Message != "no fix available (not-fixed)" {
+		t.Errorf("unfixable test case = %+v, want it skipped", nofix)
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	updates, results, unfixable := testFindings()
+	r := New(io.Discard)
+	r.Unfixable = unfixable
+	rep := r.BuildReport(updates, results)
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	paths, err := rep.WriteDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(Formats)-1 {
+		t.Errorf("wrote %d files, want one for each of the %d formats but template", len(paths), len(Formats)-1)
+	}
+	for _, format := range Formats {
+		if _, ok := FileNames[format]; !ok && format != "template" {
+			t.Errorf("format %s has no file name", format)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(paths) {
+		t.Errorf("directory has %d entries, want %d with no temporary files left", len(entries), len(paths))
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("%s is missing or empty: %v", path, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileNames["json"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Errorf("%s is not valid JSON", FileNames["json"])
+	}
+}
+
+func TestReportModulesJSON(t *testing.T) {
+	modules := []ModuleOutput{
+		{Path: "service-a", Output: render(t, "json"), Stats: ResultStats{PackagesUpdated: 1, PackagesFailed: 1, VulnerabilitiesFixed: 1, VulnerabilitiesFailed: 1}},
+		{Path: "service-b", Failed: true},
+		{Path: "service-c"},
+	}
+	var buf bytes.Buffer
+	if err := ReportModules(&buf, "json", modules); err != nil {
+		t.Fatal(err)
+	}
+
+	var got ModulesReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("modules report is not a JSON object: %v\n%s", err, buf.String())
+	}
+	if len(got.Modules) != 3 || got.Modules[0].Path != "service-a" || !got.Modules[1].Failed || got.Modules[2].Report != nil {
+		t.Errorf("modules = %+v, want service-a with a report, failed service-b, and service-c without one", got.Modules)
+	}
+	if !json.Valid(got.Modules[0].Report) {
+		t.Errorf("service-a report is not valid JSON: %s", got.Modules[0].Report)
+	}
+	want := ModulesSummary{Modules: 3, ModulesFailed: 1, VulnerabilitiesFixed: 1, VulnerabilitiesFailed: 1, PackagesUpdated: 1, PackagesFailed: 1}
+	if got.Summary != want {
+		t.Errorf("summary = %+v, want %+v", got.Summary, want)
+	}
+}
+
+func TestReportModuleListJSON(t *testing.T) {
+	modules := []ModuleOutput{
+		{Path: "service-a", Output: render(t, "json"), Stats: ResultStats{PackagesUpdated: 1}},
+		{Path: "service-b", Failed: true},
+	}
+	var buf bytes.Buffer
+	if err := ReportModuleList(&buf, "json", modules); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []ModuleReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("module list report is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].Path != "service-a" || !json.Valid(got[0].Report) || got[1].Path != "service-b" || !got[1].Failed {
+		t.Errorf("entries = %+v, want service-a with its report and failed service-b, in order", got)
+	}
+}
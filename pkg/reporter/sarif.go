@@ -0,0 +1,185 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// SARIF 2.1.0 schema and version
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifLevels maps severity ranks to SARIF result levels
+var sarifLevels = map[int]string{
+	5: "error",   // Critical
+	4: "error",   // High
+	3: "warning", // Medium
+	2: "note",    // Low
+	1: "note",    // Negligible
+}
+
+// sarifSecuritySeverities maps severity ranks to the numeric scores GitHub code scanning
+// uses to bucket security alerts
+var sarifSecuritySeverities = map[int]string{
+	5: "9.5",
+	4: "8.0",
+	3: "5.5",
+	2: "2.0",
+	1: "0.1",
+}
+
+// sarifLog is the root of a SARIF document
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is a single invocation of grump
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes grump and the rules it reported
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver names the tool and lists one rule per advisory
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a single advisory
+type sarifRule struct {
+	ID               string          `json:"id"`
+	ShortDescription sarifMessage    `json:"shortDescription"`
+	Properties       sarifProperties `json:"properties"`
+}
+
+// sarifProperties are the rule properties read by GitHub code scanning
+type sarifProperties struct {
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	Tags             []string `json:"tags"`
+}
+
+// sarifResult is a single vulnerability finding
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// sarifMessage is a plain text message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifLocation points at go.mod and names the affected module
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+// sarifPhysicalLocation is a file in the repository
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+// sarifArtifactLocation is a repository-relative file path
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLogicalLocation is the affected module
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a grype severity label to a SARIF level; unknown severities are warnings
+func sarifLevel(severity string) string {
+	if level, ok := sarifLevels[scanner.SeverityRank(severity)]; ok {
+		return level
+	}
+	return "warning"
+}
+
+// reportSARIF outputs results as a SARIF 2.1.0 log for GitHub code scanning.
+// Every finding, fixed or not, is a result located at go.mod; the message records whether
+// grump patched it.
+func (r *Reporter) reportSARIF(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "grump",
+			Version:        r.toolVersion(),
+			InformationURI: "https://github.com/divolgin/grump",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	addResult := func(name, version, vulnID, severity, message string) {
+		if !rules[vulnID] {
+			rules[vulnID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               vulnID,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("%s vulnerability %s", severity, vulnID)},
+				Properties: sarifProperties{
+					SecuritySeverity: sarifSecuritySeverities[scanner.SeverityRank(severity)],
+					Tags:             []string{"security", "vulnerability", "go"},
+				},
+			})
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  vulnID,
+			Level:   sarifLevel(severity),
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "go.mod"},
+				},
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               name,
+					FullyQualifiedName: name + "@" + version,
+					Kind:               "module",
+				}},
+			}},
+		})
+	}
+
+	for _, update := range updates {
+		message := fmt.Sprintf("%s %s is affected by %s; fixed in %s", update.Name, update.CurrentVersion, update.VulnID, update.TargetVersion)
+		if status, _ := packageStatus(update.Name, results); status == StatusFixed {
+			message += " (updated by grump)"
+		}
+		addResult(update.Name, update.CurrentVersion, update.VulnID, update.SeverityForGating(), message)
+	}
+	for _, vuln := range r.Unfixable {
+		message := fmt.Sprintf("%s %s is affected by %s; no fix is available", vuln.Name, vuln.Version, vuln.VulnID)
+		addResult(vuln.Name, vuln.Version, vuln.VulnID, vuln.Severity, message)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// templateFuncs are the helper functions available to custom report templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ParseTemplate parses a custom report template, see ReportResultsTo
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("report").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ReportResultsTo renders the results to w with a custom text/template. The template is
+// executed against the Report, the same structure the JSON format encodes, so per-update data
+// is available as .Updates. The functions join, lower, and upper are available, e.g.
+//
+//	{{range .Updates}}{{.Package}} {{.CurrentVersion}} -> {{.TargetVersion}} ({{.VulnID}})
+//	{{end}}
+func (r *Reporter) ReportResultsTo(w io.Writer, text string, updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, r.BuildReport(updates, results)); err != nil {
+		return fmt.Errorf("failed to render report template: %w", err)
+	}
+	return nil
+}
+
+// reportTemplate outputs results rendered with the Template, see ReportResultsTo
+func (r *Reporter) reportTemplate(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	if r.Template == "" {
+		return fmt.Errorf("the template format requires a template")
+	}
+	return r.ReportResultsTo(r.writer, r.Template, updates, results)
+}
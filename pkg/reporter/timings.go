@@ -0,0 +1,40 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timings records how long the stages of a run took, in milliseconds
+type Timings struct {
+	DBLoadMS int64 `json:"db_load_ms"`
+	SBOMMS   int64 `json:"sbom_ms"`
+	MatchMS  int64 `json:"match_ms"`
+	PatchMS  int64 `json:"patch_ms"`
+	TotalMS  int64 `json:"total_ms"`
+}
+
+// NewTimings converts stage durations to a Timings
+func NewTimings(dbLoad, sbom, match, patch, total time.Duration) *Timings {
+	return &Timings{
+		DBLoadMS: dbLoad.Milliseconds(),
+		SBOMMS:   sbom.Milliseconds(),
+		MatchMS:  match.Milliseconds(),
+		PatchMS:  patch.Milliseconds(),
+		TotalMS:  total.Milliseconds(),
+	}
+}
+
+// reportTimingsText prints the stage timings as a single footer line
+func (r *Reporter) reportTimingsText() {
+	seconds := func(ms int64) string {
+		return fmt.Sprintf("%.1fs", float64(ms)/1000)
+	}
+	fmt.Fprintf(r.writer, "\nTimings: database %s, SBOM %s, matching %s, patching %s, total %s\n",
+		seconds(r.Timings.DBLoadMS),
+		seconds(r.Timings.SBOMMS),
+		seconds(r.Timings.MatchMS),
+		seconds(r.Timings.PatchMS),
+		seconds(r.Timings.TotalMS),
+	)
+}
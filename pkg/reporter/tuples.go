@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// reportTuples outputs one tab-separated line per finding for shell pipelines:
+//
+//	module	current->target	vulnerability_id	severity	status
+//
+// Findings without a fix use "-" as the target and "no-fix" as the status.
+func (r *Reporter) reportTuples(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	for _, update := range updates {
+		status, _ := packageStatus(update.Name, results)
+		fmt.Fprintf(r.writer, "%s\t%s->%s\t%s\t%s\t%s\n",
+			update.Name,
+			update.CurrentVersion,
+			update.TargetVersion,
+			update.VulnID,
+			update.SeverityForGating(),
+			status,
+		)
+	}
+
+	for _, vuln := range r.Unfixable {
+		fmt.Fprintf(r.writer, "%s\t%s->-\t%s\t%s\t%s\n",
+			vuln.Name,
+			vuln.Version,
+			vuln.VulnID,
+			vuln.Severity,
+			StatusNoFix,
+		)
+	}
+
+	return nil
+}
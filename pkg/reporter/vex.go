@@ -0,0 +1,181 @@
+package reporter
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/divolgin/grump/pkg/patcher"
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// CycloneDX spec version of VEX documents
+const vexSpecVersion = "1.5"
+
+// CycloneDX analysis states and responses used in VEX documents
+const (
+	vexStateResolved    = "resolved"
+	vexStateExploitable = "exploitable"
+
+	vexResponseUpdate    = "update"
+	vexResponseCanNotFix = "can_not_fix"
+)
+
+// vexDocument is a CycloneDX BOM carrying only components and vulnerabilities
+type vexDocument struct {
+	BOMFormat       string             `json:"bomFormat"`
+	SpecVersion     string             `json:"specVersion"`
+	SerialNumber    string             `json:"serialNumber"`
+	Version         int                `json:"version"`
+	Metadata        vexMetadata        `json:"metadata"`
+	Components      []vexComponent     `json:"components"`
+	Vulnerabilities []vexVulnerability `json:"vulnerabilities"`
+}
+
+// vexMetadata records when and by which tool the document was produced
+type vexMetadata struct {
+	Timestamp string   `json:"timestamp"`
+	Tools     vexTools `json:"tools"`
+}
+
+// vexTools lists the tools that produced the document
+type vexTools struct {
+	Components []vexComponent `json:"components"`
+}
+
+// vexComponent is an affected Go module, or the tool itself in metadata
+type vexComponent struct {
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// vexVulnerability is a single vulnerability in a single module and its remediation state
+type vexVulnerability struct {
+	ID       string        `json:"id"`
+	Ratings  []vexRating   `json:"ratings,omitempty"`
+	Analysis vexAnalysis   `json:"analysis"`
+	Affects  []vexAffected `json:"affects"`
+}
+
+// vexRating is the severity of a vulnerability
+type vexRating struct {
+	Severity string `json:"severity"`
+}
+
+// vexAnalysis states whether the vulnerability was remediated
+type vexAnalysis struct {
+	State    string   `json:"state"`
+	Response []string `json:"response,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// vexAffected references the affected component
+type vexAffected struct {
+	Ref string `json:"ref"`
+}
+
+// vexSeverity maps a grype severity label to a CycloneDX rating severity
+func vexSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high", "medium", "low":
+		return strings.ToLower(severity)
+	case "negligible":
+		return "info"
+	default:
+		return "unknown"
+	}
+}
+
+// golangPURL returns the package URL of a Go module version
+func golangPURL(name, version string) string {
+	return fmt.Sprintf("pkg:golang/%s@%s", name, version)
+}
+
+// newSerialNumber returns a random RFC 4122 URN for the document
+func newSerialNumber() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// reportVEX outputs results as a CycloneDX VEX document recording the remediation state of
+// every finding. Patched vulnerabilities are resolved; failed, skipped, and unfixable ones
+// remain exploitable. In a dry run nothing is patched, so nothing is reported as resolved.
+func (r *Reporter) reportVEX(updates []scanner.PackageUpdate, results []patcher.UpdateResult) error {
+	doc := vexDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  vexSpecVersion,
+		SerialNumber: newSerialNumber(),
+		Version:      1,
+		Metadata: vexMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: vexTools{Components: []vexComponent{{
+				Type:    "application",
+				Name:    "grump",
+				Version: r.toolVersion(),
+			}}},
+		},
+		Components:      []vexComponent{},
+		Vulnerabilities: []vexVulnerability{},
+	}
+
+	components := make(map[string]bool)
+	addVulnerability := func(name, version, vulnID, severity string, analysis vexAnalysis) {
+		ref := golangPURL(name, version)
+		if !components[ref] {
+			components[ref] = true
+			doc.Components = append(doc.Components, vexComponent{
+				BOMRef:  ref,
+				Type:    "library",
+				Name:    name,
+				Version: version,
+				PURL:    ref,
+			})
+		}
+
+		doc.Vulnerabilities = append(doc.Vulnerabilities, vexVulnerability{
+			ID:       vulnID,
+			Ratings:  []vexRating{{Severity: vexSeverity(severity)}},
+			Analysis: analysis,
+			Affects:  []vexAffected{{Ref: ref}},
+		})
+	}
+
+	for _, update := range updates {
+		analysis := vexAnalysis{
+			State:    vexStateExploitable,
+			Response: []string{vexResponseUpdate},
+		}
+		status, detail := packageStatus(update.Name, results)
+		switch {
+		case status == StatusFixed && r.DryRun:
+			analysis.Detail = fmt.Sprintf("Would be updated to %s (dry run)", update.TargetVersion)
+		case status == StatusFixed:
+			analysis.State = vexStateResolved
+			analysis.Detail = fmt.Sprintf("Updated to %s by grump", update.TargetVersion)
+		case detail != "":
+			analysis.Detail = fmt.Sprintf("Not updated to %s: %s", update.TargetVersion, detail)
+		default:
+			analysis.Detail = fmt.Sprintf("Not updated to %s", update.TargetVersion)
+		}
+		addVulnerability(update.Name, update.CurrentVersion, update.VulnID, update.Severity, analysis)
+	}
+	for _, vuln := range r.Unfixable {
+		addVulnerability(vuln.Name, vuln.Version, vuln.VulnID, vuln.Severity, vexAnalysis{
+			State:    vexStateExploitable,
+			Response: []string{vexResponseCanNotFix},
+			Detail:   fmt.Sprintf("No fix available (fix state: %s)", vuln.FixState),
+		})
+	}
+
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
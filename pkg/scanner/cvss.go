@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/anchore/grype/grype/vulnerability"
+)
+
+// cvssVersionPreference orders CVSS versions from most to least preferred when a
+// vulnerability is scored under several. v3.1 corrects v3.0, and v2 is coarsest.
+var cvssVersionPreference = []string{"3.1", "3.0", "4.0", "2.0"}
+
+// cvssSeverity derives a severity label from the preferred CVSS base score in scores, using
+// the ranges of the scoring version. It returns "" when no usable score is present.
+func cvssSeverity(scores []vulnerability.Cvss) string {
+	for _, version := range cvssVersionPreference {
+		for _, cvss := range scores {
+			if strings.TrimPrefix(strings.ToLower(cvss.Version), "v") != version || cvss.Metrics.BaseScore <= 0 {
+				continue
+			}
+			return scoreSeverity(version, cvss.Metrics.BaseScore)
+		}
+	}
+	return ""
+}
+
+// scoreSeverity maps a CVSS base score to a severity label. v2 has no critical rating, and
+// its high rating starts at 7.0 like the later versions.
+func scoreSeverity(version string, score float64) string {
+	switch {
+	case score >= 9.0 && version != "2.0":
+		return "Critical"
+	case score >= 7.0:
+		return "High"
+	case score >= 4.0:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
@@ -0,0 +1,20 @@
+package scanner
+
+import "errors"
+
+// Errors returned by New and the scan methods, wrapping the underlying cause, so that callers
+// can tell failure modes apart with errors.Is
+var (
+	// ErrDBLoad is returned when the vulnerability database can't be loaded or updated
+	ErrDBLoad = errors.New("failed to load vulnerability database")
+	// ErrGrypeConfig is returned when the grype config file can't be read or parsed
+	ErrGrypeConfig = errors.New("failed to load grype config")
+	// ErrSourceCreate is returned when syft can't open the path to scan
+	ErrSourceCreate = errors.New("failed to create source")
+	// ErrSBOMCreate is returned when cataloging the source into an SBOM fails
+	ErrSBOMCreate = errors.New("failed to create SBOM")
+	// ErrInvalidSBOM is returned when an SBOM file can't be read, decoded, or has no packages
+	ErrInvalidSBOM = errors.New("invalid SBOM")
+	// ErrMatch is returned when matching packages against the vulnerability database fails
+	ErrMatch = errors.New("failed to find vulnerabilities")
+)
@@ -0,0 +1,20 @@
+package scanner
+
+// Explanation records how an update's target version was chosen, for audits
+type Explanation struct {
+	// FixState is the advisory's fix state, e.g. "fixed"
+	FixState string
+	// AdvisoryVersions are the fix versions exactly as the advisory lists them
+	AdvisoryVersions []string
+	// Strategy is the fix version strategy that chose among the valid fix versions
+	Strategy FixVersionStrategy
+	// Selected is the fix version the strategy chose, and SelectedFrom its form in the
+	// advisory before it was normalized to the module's version scheme
+	Selected     string
+	SelectedFrom string
+}
+
+// Normalized reports whether the selected version was rewritten from its advisory form
+func (e *Explanation) Normalized() bool {
+	return e.Selected != e.SelectedFrom
+}
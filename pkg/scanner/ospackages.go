@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/anchore/grype/grype/distro"
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	"github.com/anchore/syft/syft"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+)
+
+// OSVulnerability is a vulnerability in a package that isn't a Go module, such as a distro
+// package. grump reports these but can't patch them.
+type OSVulnerability struct {
+	Name        string   // e.g., "openssl"
+	Version     string   // e.g., "3.1.4-r0"
+	Type        string   // syft package type, e.g., "apk", "deb"
+	VulnID      string   // e.g., "CVE-2024-0727"
+	Severity    string   // e.g., "Medium", "High"
+	FixState    string   // e.g., "fixed", "not-fixed"
+	FixVersions []string // versions that fix the vulnerability, if any
+}
+
+// SetIncludeOSPackages also matches packages that aren't Go modules. ScanWithContext then
+// catalogs the directory containing go.mod as well, and the distro found in an SBOM is used
+// to match its OS packages. Findings are reported with GetOSVulnerabilities.
+func (s *Scanner) SetIncludeOSPackages(include bool) {
+	s.includeOSPackages = include
+}
+
+// scanOSPackages catalogs dir and matches the packages found there that aren't Go modules.
+// Go modules are left to the go.mod scan, which only sees the module being patched.
+func (s *Scanner) scanOSPackages(ctx context.Context, dir string) (match.Matches, []pkg.Package, error) {
+	sbomStart := time.Now()
+	sbomResult, err := s.catalogDir(ctx, dir)
+	if err != nil {
+		return match.NewMatches(), nil, err
+	}
+	s.timings.SBOM += time.Since(sbomStart)
+
+	var packages []pkg.Package
+	for _, p := range pkg.FromCollection(sbomResult.Artifacts.Packages, pkg.SynthesisConfig{}) {
+		if p.Type != syftPkg.GoModulePkg {
+			packages = append(packages, p)
+		}
+	}
+	matches, err := s.matchPackages(packages, s.packageContext(sbomResult))
+	return matches, packages, err
+}
+
+// catalogDir creates an SBOM of dir, leaving out the paths matching the exclude patterns
+func (s *Scanner) catalogDir(ctx context.Context, dir string) (*sbom.SBOM, error) {
+	cfg := syft.DefaultGetSourceConfig()
+	if len(s.excludePaths) > 0 {
+		// Syft rewrites the patterns in place, so each scan gets its own copy
+		cfg = cfg.WithExcludeConfig(source.ExcludeConfig{Paths: slices.Clone(s.excludePaths)})
+	}
+	src, err := syft.GetSource(ctx, dir, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w for OS packages: %w", ErrSourceCreate, err)
+	}
+	defer src.Close()
+
+	sbomResult, err := syft.CreateSBOM(ctx, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w for OS packages: %w", ErrSBOMCreate, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("scan aborted: %w", err)
+	}
+	return sbomResult, nil
+}
+
+// packageContext describes the scanned source to the matchers. The distro is only taken
+// from the SBOM when OS packages are included; Go module matching doesn't use it.
+func (s *Scanner) packageContext(sbomResult *sbom.SBOM) pkg.Context {
+	pkgContext := pkg.Context{
+		Source: &sbomResult.Source,
+	}
+	if s.includeOSPackages && sbomResult.Artifacts.LinuxDistribution != nil {
+		pkgContext.Distro = distro.FromRelease(sbomResult.Artifacts.LinuxDistribution, nil)
+	}
+	return pkgContext
+}
+
+// GetOSVulnerabilities extracts vulnerabilities in packages that aren't Go modules, see
+// SetIncludeOSPackages
+func (s *Scanner) GetOSVulnerabilities(matches match.Matches) []OSVulnerability {
+	var vulns []OSVulnerability
+
+	for m := range matches.Enumerate() {
+		if m.Package.Type == syftPkg.GoModulePkg {
+			continue
+		}
+
+		var aliases []string
+		for _, related := range m.Vulnerability.RelatedVulnerabilities {
+			aliases = append(aliases, related.ID)
+		}
+		if s.ignoredVulnerability(m.Package.Name, m.Vulnerability.ID, aliases) {
+			continue
+		}
+
+		fixState := m.Vulnerability.Fix.State
+		if fixState == "" {
+			fixState = vulnerability.FixStateUnknown
+		}
+		if !s.includesFixState(fixState) {
+			continue
+		}
+
+		vulns = append(vulns, OSVulnerability{
+			Name:        m.Package.Name,
+			Version:     m.Package.Version,
+			Type:        string(m.Package.Type),
+			VulnID:      m.Vulnerability.ID,
+			Severity:    s.overrideSeverity(m.Package.Name, matchSeverity(m), m.Vulnerability.ID, aliases),
+			FixState:    string(fixState),
+			FixVersions: m.Vulnerability.Fix.Versions,
+		})
+	}
+
+	// Like the fixable updates, keep the order stable across runs
+	sort.Slice(vulns, func(i, j int) bool {
+		a, b := vulns[i], vulns[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.VulnID != b.VulnID {
+			return a.VulnID < b.VulnID
+		}
+		return a.Version < b.Version
+	})
+	return vulns
+}
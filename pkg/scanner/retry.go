@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anchore/grype/grype/vulnerability"
+)
+
+// Backoff between vulnerability database load attempts: the first retry waits
+// dbRetryInitialDelay, and each following one waits twice as long, up to dbRetryMaxDelay
+const (
+	dbRetryInitialDelay = 2 * time.Second
+	dbRetryMaxDelay     = 30 * time.Second
+)
+
+// transientMessages are fragments of error messages from network failures that the database
+// download reports without wrapping a typed error
+var transientMessages = []string{
+	"connection reset",
+	"connection refused",
+	"timeout",
+	"timed out",
+	"temporary failure",
+	"no such host",
+	"unexpected eof",
+	"status code: 5",
+	"status 5",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+// isTransientError reports whether a database load failure looks like a network problem that
+// may go away on its own. Other failures, such as a corrupt or incompatible database, are not
+// retried.
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range transientMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadVulnerabilityDBWithRetry loads the vulnerability database like loadVulnerabilityDB,
+// retrying transient failures up to opts.DBRetries times with exponential backoff
+func loadVulnerabilityDBWithRetry(opts Options) (vulnerability.Provider, *vulnerability.ProviderStatus, error) {
+	retries := opts.DBRetries
+	delay := dbRetryInitialDelay
+	for attempt := 1; ; attempt++ {
+		store, status, err := loadVulnerabilityDB(opts)
+		if err == nil || attempt > retries || !isTransientError(err) {
+			return store, status, err
+		}
+
+		slog.Warn("Loading the vulnerability database failed, retrying",
+			"attempt", attempt, "retries", retries, "delay", delay, "error", err)
+		time.Sleep(delay)
+		delay = min(delay*2, dbRetryMaxDelay)
+	}
+}
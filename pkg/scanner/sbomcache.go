@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/anchore/syft/syft/format"
+	"github.com/anchore/syft/syft/format/syftjson"
+	"github.com/anchore/syft/syft/sbom"
+)
+
+// SetSBOMCacheDir caches the SBOMs of scanned go.mod files in dir, so that an unchanged
+// module isn't cataloged again. Entries are keyed by the SHA-256 of go.sum, and of go.mod
+// which the SBOM is cataloged from, so any change to them is a cache miss. Modules without
+// a go.sum aren't cached. An empty dir disables the cache, the default.
+func (s *Scanner) SetSBOMCacheDir(dir string) {
+	s.sbomCacheDir = dir
+}
+
+// sbomCacheKey returns the cache key of the module at goModPath, "" if it can't be cached
+func (s *Scanner) sbomCacheKey(goModPath string) string {
+	if s.sbomCacheDir == "" {
+		return ""
+	}
+
+	goSum, err := os.ReadFile(filepath.Join(filepath.Dir(goModPath), "go.sum"))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to read go.sum, not caching the SBOM", "error", err)
+		}
+		return ""
+	}
+	goMod, err := os.ReadFile(goModPath)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write(goSum)
+	h.Write(goMod)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sbomCachePath returns the path of the cache entry for key
+func (s *Scanner) sbomCachePath(key string) string {
+	return filepath.Join(s.sbomCacheDir, "sbom-"+key+".json")
+}
+
+// readCachedSBOM returns the cached SBOM for key, nil if there is none or it can't be decoded
+func (s *Scanner) readCachedSBOM(key string) *sbom.SBOM {
+	path := s.sbomCachePath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	sbomResult, formatID, _, err := format.Decode(bytes.NewReader(data))
+	if err != nil || sbomResult == nil || formatID == "" {
+		slog.Warn("Ignoring unreadable cached SBOM", "path", path, "error", err)
+		return nil
+	}
+	slog.Debug("Using cached SBOM", "path", path)
+	return sbomResult
+}
+
+// writeCachedSBOM stores the SBOM under key. The entry is written to a temporary file and
+// renamed so that concurrent runs never read a partial entry.
+func (s *Scanner) writeCachedSBOM(key string, sbomResult *sbom.SBOM) error {
+	data, err := format.Encode(*sbomResult, syftjson.NewFormatEncoder())
+	if err != nil {
+		return fmt.Errorf("failed to encode SBOM: %w", err)
+	}
+	if err := os.MkdirAll(s.sbomCacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := s.sbomCachePath(key)
+	tmp, err := os.CreateTemp(s.sbomCacheDir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write SBOM cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write SBOM cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write SBOM cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -3,19 +3,30 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/anchore/clio"
 	"github.com/anchore/grype/grype"
+	v6 "github.com/anchore/grype/grype/db/v6"
 	"github.com/anchore/grype/grype/db/v6/distribution"
 	"github.com/anchore/grype/grype/db/v6/installation"
 	"github.com/anchore/grype/grype/match"
 	"github.com/anchore/grype/grype/matcher"
+	"github.com/anchore/grype/grype/matcher/golang"
 	"github.com/anchore/grype/grype/pkg"
 	"github.com/anchore/grype/grype/vulnerability"
 	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/format"
 	syftPkg "github.com/anchore/syft/syft/pkg"
+	"github.com/anchore/syft/syft/sbom"
+	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 	"gopkg.in/yaml.v3"
@@ -28,12 +39,276 @@ type PackageUpdate struct {
 	TargetVersion  string // e.g., "0.5.15"
 	VulnID         string // e.g., "GHSA-jc7w-c686-c4v9"
 	Severity       string // e.g., "Medium", "High"
+	// LatestAvailable is the newest published release when it is newer than TargetVersion.
+	// It is informational only and is populated by the patcher on request.
+	LatestAvailable string // e.g., "v0.5.20"
+	// EffectiveSeverity is the severity used for gating and sorting. It starts out equal to
+	// Severity and may be escalated based on exploitation data.
+	EffectiveSeverity string
+	Aliases           []string // related advisory IDs, e.g., the CVE for a GHSA
+	KnownExploited    bool     // listed as known exploited by the vulnerability database
+	EPSS              float64  // exploit prediction score (0-1), 0 when unknown
+	// Confidence indicates how reliable the match is, see MatchConfidence
+	Confidence string // "high", "medium", or "low"
+	// FixVersions are all valid fix versions listed by the advisories, in ascending order.
+	// TargetVersion is one of them, chosen by the fix version strategy.
+	FixVersions []string
+	// Explanation records how TargetVersion was chosen from the advisory that requires it
+	Explanation *Explanation
+	// VulnIDs lists every vulnerability resolved by this update when several were coalesced
+	// into one, see CoalesceUpdates. It includes VulnID.
+	VulnIDs []string
+	// Direct is set when the scanned go.mod requires the module directly rather than as an
+	// "// indirect" dependency. Without a go.mod, as when scanning a binary, every update is direct.
+	Direct bool
+	// Replace describes the go.mod replace directive that overrides this module, if any.
+	// Bumping the require line has no effect on a replaced module, so it isn't patched.
+	Replace string // e.g., "replaced by local path ../xz"
+	// Published is when the vulnerability was first published, zero when unknown. It comes
+	// from the vulnerability database; osv.Dates can fill in dates the database lacks.
+	Published time.Time
+}
+
+// Match confidence levels
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// confidenceRanks orders confidence levels from least to most reliable
+var confidenceRanks = map[string]int{
+	ConfidenceLow:    1,
+	ConfidenceMedium: 2,
+	ConfidenceHigh:   3,
+}
+
+// ConfidenceRank returns the relative rank of a confidence level; unknown levels rank 0
+func ConfidenceRank(confidence string) int {
+	return confidenceRanks[strings.ToLower(confidence)]
+}
+
+// MatchConfidence derives a normalized confidence from grype's match details.
+// A direct match of the package name and version against an advisory is high confidence,
+// an indirect match (e.g. through a related package) is medium, and a CPE-based match,
+// which often misattributes advisories to Go modules, is low. The best detail wins.
+func MatchConfidence(details match.Details) string {
+	best := ""
+	for _, detail := range details {
+		confidence := ConfidenceLow
+		switch detail.Type {
+		case match.ExactDirectMatch:
+			confidence = ConfidenceHigh
+		case match.ExactIndirectMatch:
+			confidence = ConfidenceMedium
+		}
+		if ConfidenceRank(confidence) > ConfidenceRank(best) {
+			best = confidence
+		}
+	}
+	if best == "" {
+		return ConfidenceLow
+	}
+	return best
+}
+
+// SeverityForGating returns the severity used for thresholds and ordering:
+// EffectiveSeverity when set, otherwise the raw Severity
+func (u PackageUpdate) SeverityForGating() string {
+	if u.EffectiveSeverity != "" {
+		return u.EffectiveSeverity
+	}
+	return u.Severity
+}
+
+// CoalesceUpdates merges updates for the same package into one update per package that targets
+// the highest required version, so each module is bumped once. The merged update lists every
+// vulnerability it resolves in VulnIDs and carries the most severe rating and the highest
+// confidence of its parts. Packages keep the order in which they first appear.
+func CoalesceUpdates(updates []PackageUpdate) []PackageUpdate {
+	var coalesced []PackageUpdate
+	index := make(map[string]int)
+
+	for _, upd := range updates {
+		i, exists := index[upd.Name]
+		if !exists {
+			index[upd.Name] = len(coalesced)
+			upd.VulnIDs = append([]string{upd.VulnID}, upd.VulnIDs...)
+			upd.Aliases = append([]string(nil), upd.Aliases...)
+			upd.FixVersions = append([]string(nil), upd.FixVersions...)
+			coalesced = append(coalesced, upd)
+			continue
+		}
+
+		merged := &coalesced[i]
+		if semver.Compare(upd.TargetVersion, merged.TargetVersion) > 0 {
+			merged.TargetVersion = upd.TargetVersion
+			merged.Explanation = upd.Explanation
+		}
+		if !containsString(merged.VulnIDs, upd.VulnID) {
+			merged.VulnIDs = append(merged.VulnIDs, upd.VulnID)
+		}
+		for _, alias := range upd.Aliases {
+			if !containsString(merged.Aliases, alias) {
+				merged.Aliases = append(merged.Aliases, alias)
+			}
+		}
+		if SeverityRank(upd.Severity) > SeverityRank(merged.Severity) {
+			merged.Severity = upd.Severity
+		}
+		if SeverityRank(upd.SeverityForGating()) > SeverityRank(merged.SeverityForGating()) {
+			merged.EffectiveSeverity = upd.SeverityForGating()
+		}
+		if ConfidenceRank(upd.Confidence) > ConfidenceRank(merged.Confidence) {
+			merged.Confidence = upd.Confidence
+		}
+		if upd.EPSS > merged.EPSS {
+			merged.EPSS = upd.EPSS
+		}
+		merged.KnownExploited = merged.KnownExploited || upd.KnownExploited
+		merged.FixVersions = mergeFixVersions(merged.FixVersions, upd.FixVersions)
+		if merged.LatestAvailable == "" {
+			merged.LatestAvailable = upd.LatestAvailable
+		}
+	}
+
+	return coalesced
+}
+
+// mergeFixVersions returns the union of two ascending version lists, in ascending order
+func mergeFixVersions(versions, more []string) []string {
+	for _, v := range more {
+		if !containsString(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+	semver.Sort(versions)
+	return versions
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// UnfixableVulnerability represents a Go module vulnerability that has no fix available
+type UnfixableVulnerability struct {
+	Name     string // e.g., "github.com/ulikunitz/xz"
+	Version  string // e.g., "v0.5.12"
+	VulnID   string // e.g., "GHSA-jc7w-c686-c4v9"
+	Severity string // e.g., "Medium", "High"
+	FixState string // e.g., "not-fixed", "wont-fix", "unknown"
+}
+
+// severityRanks orders grype severity labels from least to most severe.
+// Labels that are not listed, including "Unknown", rank lowest.
+var severityRanks = map[string]int{
+	"negligible": 1,
+	"low":        2,
+	"medium":     3,
+	"high":       4,
+	"critical":   5,
+}
+
+// SeverityRank returns the relative rank of a severity label, case-insensitively.
+// Higher ranks are more severe; unknown labels rank 0.
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToLower(severity)]
+}
+
+// meetsSeverity reports whether severity is at or above the minimum severity.
+// "Unknown" and other unlisted labels only meet a negligible threshold.
+func meetsSeverity(severity, minSeverity string) bool {
+	minRank := SeverityRank(minSeverity)
+	if minRank <= severityRanks["negligible"] {
+		return true
+	}
+	return SeverityRank(severity) >= minRank
+}
+
+// FixVersionStrategy selects which of an advisory's fix versions to update to
+type FixVersionStrategy string
+
+// Fix version strategies
+const (
+	// FixLowest picks the lowest fix version, the smallest change that resolves the advisory
+	FixLowest FixVersionStrategy = "lowest"
+	// FixHighest picks the highest fix version, to avoid patching the same module again soon
+	FixHighest FixVersionStrategy = "highest"
+)
+
+// Validate checks that the strategy is known; empty means FixLowest
+func (f FixVersionStrategy) Validate() error {
+	switch f {
+	case "", FixLowest, FixHighest:
+		return nil
+	}
+	return fmt.Errorf("invalid fix strategy %q: must be lowest or highest", string(f))
 }
 
 // Scanner wraps Grype functionality
 type Scanner struct {
 	store       vulnerability.Provider
 	ignoreRules []match.IgnoreRule
+	// normalizeByCVE makes grype key findings by CVE, collapsing GHSA/CVE pairs
+	normalizeByCVE bool
+	// mainModule is the module path declared by the most recently scanned go.mod
+	mainModule string
+	// replaces are the replace directives of the most recently scanned go.mod
+	replaces []*modfile.Replace
+	// requires maps the modules required by the most recently scanned go.mod to whether they
+	// are direct dependencies; nil when no go.mod was scanned
+	requires map[string]bool
+	// dbStatus describes the loaded vulnerability database
+	dbStatus *vulnerability.ProviderStatus
+	// minSeverity drops fixable updates below this severity, see SetMinSeverity
+	minSeverity string
+	// escalate raises the effective severity of updates before minSeverity applies, see SetEscalation
+	escalate func([]PackageUpdate)
+	// alwaysFix are module patterns exempt from minSeverity, see SetAlwaysFix
+	alwaysFix []string
+	// severityOverrides maps upper-cased vulnerability IDs to severities, see SetSeverityOverrides
+	severityOverrides map[string]string
+	// ignoredVulns are vulnerability ID patterns to drop, see SetIgnoredVulnerabilities
+	ignoredVulns []string
+	// ignoredModules are module patterns whose findings are dropped, see SetIgnoredModules
+	ignoredModules []string
+	// fixStrategy selects among multiple fix versions, see SetFixVersionStrategy
+	fixStrategy FixVersionStrategy
+	// fixStates limits the reported findings to these fix states, see SetFixStates
+	fixStates map[vulnerability.FixState]bool
+	// goOnly matches packages with the Go module matcher alone, see SetGoOnly
+	goOnly bool
+	// requireInGoMod drops updates for modules go.mod doesn't require, see SetRequireInGoMod
+	requireInGoMod bool
+	// includeOSPackages also matches packages that aren't Go modules, see SetIncludeOSPackages
+	includeOSPackages bool
+	// excludePaths are glob patterns of paths left out of cataloging, see SetExcludePaths
+	excludePaths []string
+	// sbomCacheDir holds SBOMs of scanned modules, see SetSBOMCacheDir
+	sbomCacheDir string
+	// timings records how long loading the database and the most recent scan took
+	timings ScanTimings
+	// matchMu serializes matching against the store, which is shared between clones.
+	// Grype doesn't document its providers as safe for concurrent use, so only SBOM
+	// generation runs in parallel.
+	matchMu *sync.Mutex
+}
+
+// ScanTimings records how long the stages of a scan took
+type ScanTimings struct {
+	// DBLoad is the time spent loading, and possibly updating, the vulnerability database.
+	// The database is loaded once per Scanner and shared by its clones.
+	DBLoad time.Duration
+	// SBOM is the time spent cataloging the most recently scanned source, or decoding the SBOM
+	SBOM time.Duration
+	// Match is the time spent matching the most recent scan's packages against the database
+	Match time.Duration
 }
 
 // grypeConfig represents the grype configuration file structure
@@ -41,8 +316,70 @@ type grypeConfig struct {
 	Ignore []match.IgnoreRule `yaml:"ignore"`
 }
 
-// New creates a new Scanner instance
-func New(grypeConfigPath string) (*Scanner, error) {
+// Options configures a new Scanner. The zero value loads grype's default vulnerability
+// database, updating it first as grype does, and matches all package types without ignore
+// rules. Settings not covered here are changed with the Scanner's setters.
+type Options struct {
+	// GrypeConfigPath is a grype config file whose ignore rules are applied to all scans
+	GrypeConfigPath string
+	// NormalizeByCVE collapses findings reported under both a GHSA and a CVE ID into a
+	// single CVE-keyed finding
+	NormalizeByCVE bool
+	// DBMaxAge reuses the installed vulnerability database without checking for updates as
+	// long as it was built within this long
+	DBMaxAge time.Duration
+	// DBRetries is how many times loading the database is retried when it fails with what
+	// looks like a network error
+	DBRetries int
+	// Offline uses the installed vulnerability database as is, without checking for updates
+	// or validating its age
+	Offline bool
+	// DBDir is the directory the vulnerability database is installed in, grype's default
+	// when empty
+	DBDir string
+	// GoOnly restricts matching to the Go module matcher, see SetGoOnly
+	GoOnly bool
+	// FixVersionStrategy selects among an advisory's fix versions, see SetFixVersionStrategy
+	FixVersionStrategy FixVersionStrategy
+}
+
+// New creates a new Scanner instance and loads its vulnerability database
+func New(opts Options) (*Scanner, error) {
+	if err := opts.FixVersionStrategy.Validate(); err != nil {
+		return nil, err
+	}
+
+	dbLoadStart := time.Now()
+	dbStore, dbStatus, err := loadVulnerabilityDBWithRetry(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDBLoad, err)
+	}
+	dbLoad := time.Since(dbLoadStart)
+
+	// Load grype config if provided
+	var ignoreRules []match.IgnoreRule
+	if opts.GrypeConfigPath != "" {
+		ignoreRules, err = loadIgnoreRules(opts.GrypeConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrGrypeConfig, err)
+		}
+	}
+
+	return &Scanner{
+		store:          dbStore,
+		ignoreRules:    ignoreRules,
+		normalizeByCVE: opts.NormalizeByCVE,
+		goOnly:         opts.GoOnly,
+		fixStrategy:    opts.FixVersionStrategy,
+		dbStatus:       dbStatus,
+		timings:        ScanTimings{DBLoad: dbLoad},
+		matchMu:        &sync.Mutex{},
+	}, nil
+}
+
+// loadVulnerabilityDB loads the vulnerability database from opts.DBDir. It is updated first
+// unless opts.Offline is set or the installed copy was built within opts.DBMaxAge.
+func loadVulnerabilityDB(opts Options) (vulnerability.Provider, *vulnerability.ProviderStatus, error) {
 	// Create a minimal clio.Identification
 	id := clio.Identification{
 		Name:    "grump",
@@ -52,25 +389,279 @@ func New(grypeConfigPath string) (*Scanner, error) {
 	// Load the vulnerability database with default configs
 	distCfg := distribution.DefaultConfig()
 	installCfg := installation.DefaultConfig(id)
+	if opts.DBDir != "" {
+		installCfg.DBRootDir = opts.DBDir
+	}
 
-	dbStore, _, err := grype.LoadVulnerabilityDB(distCfg, installCfg, true)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
+	if opts.Offline {
+		installCfg.ValidateAge = false
+		return grype.LoadVulnerabilityDB(distCfg, installCfg, false)
 	}
 
-	// Load grype config if provided
-	var ignoreRules []match.IgnoreRule
-	if grypeConfigPath != "" {
-		ignoreRules, err = loadIgnoreRules(grypeConfigPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load grype config: %w", err)
+	if maxAge := opts.DBMaxAge; maxAge > 0 {
+		// Don't let grype reject a copy that is within our own limit
+		if installCfg.MaxAllowedBuiltAge < maxAge {
+			installCfg.MaxAllowedBuiltAge = maxAge
+		}
+		store, status, err := grype.LoadVulnerabilityDB(distCfg, installCfg, false)
+		if err == nil && status != nil && time.Since(status.Built) <= maxAge {
+			return store, status, nil
 		}
+		slog.Info("Installed vulnerability database is missing or too old, updating", "max_age", maxAge)
 	}
 
-	return &Scanner{
-		store:       dbStore,
-		ignoreRules: ignoreRules,
-	}, nil
+	return grype.LoadVulnerabilityDB(distCfg, installCfg, true)
+}
+
+// DBBuilt returns when the loaded vulnerability database was built, or the zero time if unknown
+func (s *Scanner) DBBuilt() time.Time {
+	if s.dbStatus == nil {
+		return time.Time{}
+	}
+	return s.dbStatus.Built
+}
+
+// Clone returns a scanner with the same configuration that shares the loaded vulnerability
+// database. Clones can scan different modules concurrently; each tracks its own main module.
+func (s *Scanner) Clone() *Scanner {
+	clone := *s
+	clone.mainModule = ""
+	clone.replaces = nil
+	clone.requires = nil
+	clone.timings = ScanTimings{DBLoad: s.timings.DBLoad}
+	return &clone
+}
+
+// Timings returns how long loading the database and the most recent scan took
+func (s *Scanner) Timings() ScanTimings {
+	return s.timings
+}
+
+// DBPath returns where the loaded vulnerability database is installed, or "" if unknown
+func (s *Scanner) DBPath() string {
+	if s.dbStatus == nil {
+		return ""
+	}
+	return s.dbStatus.Path
+}
+
+// DBSchemaVersion returns the schema version of the loaded vulnerability database, or "" if unknown
+func (s *Scanner) DBSchemaVersion() string {
+	if s.dbStatus == nil {
+		return ""
+	}
+	return s.dbStatus.SchemaVersion
+}
+
+// SetMinSeverity makes GetFixableUpdates drop updates whose effective severity, after
+// overrides and escalation, is below minSeverity (negligible, low, medium, high, or
+// critical). An empty value keeps every update.
+func (s *Scanner) SetMinSeverity(minSeverity string) error {
+	if minSeverity != "" && SeverityRank(minSeverity) == 0 {
+		return fmt.Errorf("invalid minimum severity %q: must be negligible, low, medium, high, or critical", minSeverity)
+	}
+	s.minSeverity = minSeverity
+	return nil
+}
+
+// SetEscalation makes GetFixableUpdates call escalate to raise the effective severity of its
+// updates, for example from exploitation data, before SetMinSeverity filtering
+func (s *Scanner) SetEscalation(escalate func([]PackageUpdate)) {
+	s.escalate = escalate
+}
+
+// SetSeverityOverrides reclassifies vulnerabilities. overrides maps a vulnerability ID, matched
+// case-insensitively against a finding's ID and aliases, to the severity reported instead
+// (negligible, low, medium, high, or critical). Overrides apply before SetMinSeverity filtering.
+func (s *Scanner) SetSeverityOverrides(overrides map[string]string) error {
+	normalized := make(map[string]string, len(overrides))
+	for id, severity := range overrides {
+		if SeverityRank(severity) == 0 {
+			return fmt.Errorf("invalid severity %q for %s: must be negligible, low, medium, high, or critical", severity, id)
+		}
+		lower := strings.ToLower(severity)
+		normalized[strings.ToUpper(id)] = strings.ToUpper(lower[:1]) + lower[1:]
+	}
+	s.severityOverrides = normalized
+	return nil
+}
+
+// overrideSeverity returns the severity of a finding after applying the severity overrides.
+// Each override applied is logged to stderr.
+func (s *Scanner) overrideSeverity(name, severity, vulnID string, aliases []string) string {
+	if len(s.severityOverrides) == 0 {
+		return severity
+	}
+	for _, id := range append([]string{vulnID}, aliases...) {
+		if override, ok := s.severityOverrides[strings.ToUpper(id)]; ok {
+			slog.Info("Overriding severity", "vulnerability", vulnID, "module", name, "severity", severity, "override", override)
+			return override
+		}
+	}
+	return severity
+}
+
+// applySeverityOverride reclassifies an update with the severity overrides. The effective
+// severity follows the override unless it was escalated, so gating, sorting, and -fail-on see
+// the reclassified severity.
+func (s *Scanner) applySeverityOverride(update *PackageUpdate) {
+	severity := s.overrideSeverity(update.Name, update.Severity, update.VulnID, update.Aliases)
+	if severity == update.Severity {
+		return
+	}
+	if update.EffectiveSeverity == "" || update.EffectiveSeverity == update.Severity {
+		update.EffectiveSeverity = severity
+	}
+	update.Severity = severity
+}
+
+// FixStates lists the fix states a finding can be in
+var FixStates = []vulnerability.FixState{
+	vulnerability.FixStateFixed,
+	vulnerability.FixStateNotFixed,
+	vulnerability.FixStateWontFix,
+	vulnerability.FixStateUnknown,
+}
+
+// SetFixStates limits the reported findings to those in the given fix states. Fixed findings
+// are the fixable updates; the others are reported as unfixable, see
+// GetUnfixableVulnerabilities. No states, the default, reports findings in every state.
+func (s *Scanner) SetFixStates(states []vulnerability.FixState) error {
+	s.fixStates = nil
+	for _, state := range states {
+		valid := false
+		for _, known := range FixStates {
+			valid = valid || state == known
+		}
+		if !valid {
+			return fmt.Errorf("invalid fix state %q: must be fixed, not-fixed, wont-fix, or unknown", state)
+		}
+		if s.fixStates == nil {
+			s.fixStates = make(map[vulnerability.FixState]bool)
+		}
+		s.fixStates[state] = true
+	}
+	return nil
+}
+
+// includesFixState reports whether findings in the fix state are reported, see SetFixStates
+func (s *Scanner) includesFixState(state vulnerability.FixState) bool {
+	return s.fixStates == nil || s.fixStates[state]
+}
+
+// SetGoOnly restricts matching to the Go module matcher. Packages of other ecosystems that
+// syft catalogs, such as OS or npm packages, are then left unmatched, which speeds up scanning
+// of pure Go projects.
+func (s *Scanner) SetGoOnly(goOnly bool) {
+	s.goOnly = goOnly
+}
+
+// SetExcludePaths leaves paths matching any of the glob patterns out of cataloging the project
+// directory, which only happens with SetIncludeOSPackages; Go modules are read from go.mod
+// alone. Patterns are matched relative to the directory and support "**" for any number of
+// directories, e.g. "**/testdata/**" or "vendor/**".
+func (s *Scanner) SetExcludePaths(patterns []string) error {
+	s.excludePaths = nil
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		// Syft only accepts patterns rooted with "./", "*/", or "**/"
+		pattern = strings.TrimPrefix(pattern, "/")
+		if !strings.HasPrefix(pattern, "./") && !strings.HasPrefix(pattern, "*/") && !strings.HasPrefix(pattern, "**/") {
+			pattern = "./" + pattern
+		}
+		s.excludePaths = append(s.excludePaths, pattern)
+	}
+	return nil
+}
+
+// SetAlwaysFix exempts modules matching any of the patterns from the minimum severity.
+// A pattern is an exact module path, a path ending in "/*" that matches every module below
+// that prefix (e.g. "golang.org/x/*"), or another path.Match glob.
+func (s *Scanner) SetAlwaysFix(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid always-fix pattern %q: %w", pattern, err)
+		}
+	}
+	s.alwaysFix = patterns
+	return nil
+}
+
+// isAlwaysFix reports whether the module matches an always-fix pattern
+func (s *Scanner) isAlwaysFix(name string) bool {
+	for _, pattern := range s.alwaysFix {
+		if MatchModulePattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchModulePattern reports whether a module path matches a pattern: an exact path,
+// a prefix ending in "/*" that matches any depth below it, or a path.Match glob
+func MatchModulePattern(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(name, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// SetIgnoredVulnerabilities drops findings whose vulnerability ID, or one of its aliases,
+// matches any of the patterns. A pattern is an exact ID such as "GHSA-jc7w-c686-c4v9" or
+// "CVE-2024-1234", or a glob such as "CVE-2024-*". IDs are compared case-insensitively.
+func (s *Scanner) SetIgnoredVulnerabilities(patterns []string) {
+	s.ignoredVulns = patterns
+}
+
+// SetIgnoredModules drops findings for modules matching any of the patterns, see
+// MatchModulePattern
+func (s *Scanner) SetIgnoredModules(patterns []string) {
+	s.ignoredModules = patterns
+}
+
+// ignoredVulnerability reports whether the module or the vulnerability matches an ignore
+// pattern. Each ignored finding is logged to stderr as an audit trail.
+func (s *Scanner) ignoredVulnerability(name, vulnID string, aliases []string) bool {
+	for _, pattern := range s.ignoredModules {
+		if MatchModulePattern(pattern, name) {
+			slog.Info("Ignoring vulnerability", "vulnerability", vulnID, "module", name, "pattern", pattern)
+			return true
+		}
+	}
+	ids := append([]string{vulnID}, aliases...)
+	for _, pattern := range s.ignoredVulns {
+		for _, id := range ids {
+			if matchVulnPattern(pattern, id) {
+				slog.Info("Ignoring vulnerability", "vulnerability", vulnID, "module", name, "pattern", pattern)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchVulnPattern reports whether a vulnerability ID matches an exact or glob pattern
+func matchVulnPattern(pattern, id string) bool {
+	matched, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(id))
+	return matched
+}
+
+// SetFixVersionStrategy selects which fix version GetFixableUpdates targets when an advisory
+// lists several. The default is FixLowest.
+func (s *Scanner) SetFixVersionStrategy(strategy FixVersionStrategy) error {
+	if err := strategy.Validate(); err != nil {
+		return err
+	}
+	s.fixStrategy = strategy
+	return nil
+}
+
+// AddIgnoreRules adds ignore rules on top of those loaded from the grype config file
+func (s *Scanner) AddIgnoreRules(rules ...match.IgnoreRule) {
+	s.ignoreRules = append(s.ignoreRules, rules...)
 }
 
 // loadIgnoreRules loads and parses ignore rules from a grype configuration file
@@ -90,62 +681,333 @@ func loadIgnoreRules(path string) ([]match.IgnoreRule, error) {
 
 // Scan scans a go.mod file for vulnerabilities
 func (s *Scanner) Scan(goModPath string) (match.Matches, []pkg.Package, error) {
-	ctx := context.Background()
+	return s.ScanWithContext(context.Background(), goModPath)
+}
+
+// ScanWithContext scans a go.mod file for vulnerabilities. Cancelling ctx aborts SBOM generation.
+func (s *Scanner) ScanWithContext(ctx context.Context, goModPath string) (match.Matches, []pkg.Package, error) {
+	if err := s.ScanModuleGraph(goModPath); err != nil {
+		return match.NewMatches(), nil, err
+	}
 
 	// Create a source from the go.mod file specifically (equivalent to "grype file:./go.mod")
+	matches, packages, err := s.scanFile(ctx, goModPath, s.sbomCacheKey(goModPath))
+	if err != nil || !s.includeOSPackages {
+		return matches, packages, err
+	}
+
+	osMatches, osPackages, err := s.scanOSPackages(ctx, filepath.Dir(goModPath))
+	if err != nil {
+		return match.NewMatches(), nil, err
+	}
+	matches.Add(osMatches.Sorted()...)
+	return matches, append(packages, osPackages...), nil
+}
+
+// ScanBinary scans the Go modules embedded in a compiled Go binary. A binary has no go.mod, so
+// there is no main module or replace directives to account for, and nothing can be patched.
+func (s *Scanner) ScanBinary(ctx context.Context, binaryPath string) (match.Matches, []pkg.Package, error) {
+	s.mainModule = ""
+	s.replaces = nil
+	s.requires = nil
+
+	return s.scanFile(ctx, binaryPath, "")
+}
+
+// scanFile catalogs a single file, a go.mod or a Go binary, and matches the packages found.
+// With a cacheKey the SBOM is read from, or stored in, the SBOM cache.
+func (s *Scanner) scanFile(ctx context.Context, path, cacheKey string) (match.Matches, []pkg.Package, error) {
+	s.timings.SBOM, s.timings.Match = 0, 0
+	sbomStart := time.Now()
+
+	if cacheKey != "" {
+		if sbomResult := s.readCachedSBOM(cacheKey); sbomResult != nil {
+			s.timings.SBOM = time.Since(sbomStart)
+			return s.findMatches(sbomResult)
+		}
+	}
+
 	// Note: Pass the plain file path without "file:" prefix - syft will automatically detect it as a file source
-	src, err := syft.GetSource(ctx, goModPath, syft.DefaultGetSourceConfig())
+	src, err := syft.GetSource(ctx, path, syft.DefaultGetSourceConfig())
 	if err != nil {
-		return match.NewMatches(), nil, fmt.Errorf("failed to create source: %w", err)
+		return match.NewMatches(), nil, fmt.Errorf("%w: %w", ErrSourceCreate, err)
 	}
 	defer src.Close()
 
 	// Create SBOM from source with default configuration
 	sbomResult, err := syft.CreateSBOM(ctx, src, nil)
 	if err != nil {
-		return match.NewMatches(), nil, fmt.Errorf("failed to create SBOM: %w", err)
+		return match.NewMatches(), nil, fmt.Errorf("%w: %w", ErrSBOMCreate, err)
 	}
 
-	// Convert Syft packages to Grype packages
-	grypePackages := pkg.FromCollection(sbomResult.Artifacts.Packages, pkg.SynthesisConfig{})
+	s.timings.SBOM = time.Since(sbomStart)
+
+	// Cataloging may stop early without an error when cancelled; don't match a partial SBOM
+	if err := ctx.Err(); err != nil {
+		return match.NewMatches(), nil, fmt.Errorf("scan aborted: %w", err)
+	}
+
+	if cacheKey != "" {
+		if err := s.writeCachedSBOM(cacheKey, sbomResult); err != nil {
+			slog.Warn("Failed to cache SBOM", "error", err)
+		}
+	}
+
+	return s.findMatches(sbomResult)
+}
+
+// ScanSBOM matches a previously generated SBOM file against the current vulnerability database.
+// Source cataloging is skipped entirely, which makes this the fastest way to check whether
+// new advisories affect an unchanged dependency set. The format is detected by syft's decoders,
+// so syft JSON and CycloneDX (JSON or XML) SBOMs are both accepted.
+func (s *Scanner) ScanSBOM(sbomPath string) (match.Matches, []pkg.Package, error) {
+	s.timings.SBOM, s.timings.Match = 0, 0
+	sbomStart := time.Now()
+
+	f, err := os.Open(sbomPath)
+	if err != nil {
+		return match.NewMatches(), nil, fmt.Errorf("%w: failed to open %s: %w", ErrInvalidSBOM, sbomPath, err)
+	}
+	defer f.Close()
+
+	sbomResult, formatID, _, err := format.Decode(f)
+	if err != nil {
+		return match.NewMatches(), nil, fmt.Errorf("%w: failed to decode %s: %w", ErrInvalidSBOM, sbomPath, err)
+	}
+	if sbomResult == nil || formatID == "" {
+		return match.NewMatches(), nil, fmt.Errorf("%w: unrecognized format in %s", ErrInvalidSBOM, sbomPath)
+	}
+	if sbomResult.Artifacts.Packages == nil {
+		return match.NewMatches(), nil, fmt.Errorf("%w: %s contains no packages", ErrInvalidSBOM, sbomPath)
+	}
+	s.timings.SBOM = time.Since(sbomStart)
+
+	return s.findMatches(sbomResult)
+}
+
+// BindModule checks that the scanned packages belong to the module at goModPath and records
+// it as the main module. It is used when scanning a prebuilt SBOM, where the SBOM may have
+// been generated for a different project.
+func (s *Scanner) BindModule(goModPath string, packages []pkg.Package) error {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	modFile, err := modfile.Parse(goModPath, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	required := make(map[string]bool, len(modFile.Require))
+	for _, req := range modFile.Require {
+		required[req.Mod.Path] = true
+	}
+
+	goModules, matched := 0, 0
+	for _, p := range packages {
+		if p.Type != syftPkg.GoModulePkg {
+			continue
+		}
+		goModules++
+		if required[p.Name] {
+			matched++
+		}
+	}
+
+	if goModules > 0 && matched == 0 {
+		return fmt.Errorf("SBOM does not describe module %s: none of its %d Go modules are required by %s",
+			modfile.ModulePath(data), goModules, goModPath)
+	}
+
+	s.bindModFile(modFile)
+	return nil
+}
 
-	// Create package context
-	pkgContext := pkg.Context{
-		Source: &sbomResult.Source,
-		Distro: nil, // Will be populated from SBOM if needed
+// ScanModuleGraph reads the module graph declared in a go.mod: the module itself, which is
+// never treated as its own dependency, its replace directives, and which of its requirements
+// are direct. GetFixableUpdates uses it to classify and annotate updates. ScanWithContext calls
+// it before cataloging.
+func (s *Scanner) ScanModuleGraph(goModPath string) error {
+	modFile, err := readModFile(goModPath)
+	if err != nil {
+		return err
+	}
+	s.bindModFile(modFile)
+	return nil
+}
+
+// bindModFile records the module graph of a parsed go.mod, see ScanModuleGraph
+func (s *Scanner) bindModFile(f *modfile.File) {
+	s.mainModule = ""
+	if f.Module != nil {
+		s.mainModule = f.Module.Mod.Path
+	}
+	s.replaces = f.Replace
+	s.requires = make(map[string]bool, len(f.Require))
+	for _, req := range f.Require {
+		s.requires[req.Mod.Path] = !req.Indirect
 	}
+}
 
-	// Create matchers
-	matchers := matcher.NewDefaultMatchers(matcher.Config{})
+// isDirect reports whether the module is a direct dependency of the scanned go.mod. Modules
+// missing from go.mod are indirect; without a go.mod every module counts as direct.
+func (s *Scanner) isDirect(name string) bool {
+	if s.requires == nil {
+		return true
+	}
+	return s.requires[name]
+}
+
+// SetRequireInGoMod drops fixable updates for modules that aren't in the require block of the
+// scanned go.mod, such as modules syft cataloged from test fixtures, which can't be patched.
+// Without a go.mod, as when scanning a binary, nothing is dropped.
+func (s *Scanner) SetRequireInGoMod(require bool) {
+	s.requireInGoMod = require
+}
+
+// isRequired reports whether the scanned go.mod requires the module, either under its own
+// path or as the target of a replace directive. Without a go.mod every module is required.
+func (s *Scanner) isRequired(name, version string) bool {
+	if s.requires == nil {
+		return true
+	}
+	if _, ok := s.requires[name]; ok {
+		return true
+	}
+	return s.replacement(name, version) != nil
+}
 
+// newMatchers returns the grype matchers packages are matched with, see SetGoOnly
+func (s *Scanner) newMatchers() []match.Matcher {
+	matcherConfig := matcher.Config{}
+	if s.goOnly {
+		return []match.Matcher{golang.NewGolangMatcher(matcherConfig.Golang)}
+	}
+	return matcher.NewDefaultMatchers(matcherConfig)
+}
+
+// Matchers returns the types of the grype matchers packages are matched with
+func (s *Scanner) Matchers() []string {
+	var types []string
+	for _, m := range s.newMatchers() {
+		types = append(types, string(m.Type()))
+	}
+	return types
+}
+
+// findMatches runs the grype matchers against the packages in an SBOM
+func (s *Scanner) findMatches(sbomResult *sbom.SBOM) (match.Matches, []pkg.Package, error) {
+	// Convert Syft packages to Grype packages
+	grypePackages := pkg.FromCollection(sbomResult.Artifacts.Packages, pkg.SynthesisConfig{})
+
+	matches, err := s.matchPackages(grypePackages, s.packageContext(sbomResult))
+	return matches, grypePackages, err
+}
+
+// matchPackages runs the grype matchers against packages and applies the ignore rules
+func (s *Scanner) matchPackages(grypePackages []pkg.Package, pkgContext pkg.Context) (match.Matches, error) {
 	// Find vulnerabilities using VulnerabilityMatcher
 	runner := grype.VulnerabilityMatcher{
 		VulnerabilityProvider: s.store,
-		Matchers:              matchers,
-		NormalizeByCVE:        false,
+		Matchers:              s.newMatchers(),
+		NormalizeByCVE:        s.normalizeByCVE,
 	}
 
+	s.matchMu.Lock()
+	matchStart := time.Now()
 	results, _, err := runner.FindMatches(grypePackages, pkgContext)
+	s.timings.Match += time.Since(matchStart)
+	s.matchMu.Unlock()
 	if err != nil {
-		return match.NewMatches(), nil, fmt.Errorf("failed to find vulnerabilities: %w", err)
+		return match.NewMatches(), fmt.Errorf("%w: %w", ErrMatch, err)
 	}
 
 	if results == nil {
-		return match.NewMatches(), grypePackages, nil
+		return match.NewMatches(), nil
 	}
 
 	// Apply ignore rules if configured
 	if len(s.ignoreRules) > 0 {
 		filtered, _ := match.ApplyIgnoreRules(*results, s.ignoreRules)
-		return filtered, grypePackages, nil
+		return filtered, nil
 	}
 
-	return *results, grypePackages, nil
+	return *results, nil
+}
+
+// readModFile reads and parses a go.mod file. Directives from newer Go versions don't prevent
+// a scan: if strict parsing fails, the file is parsed laxly, which keeps the module path and
+// requirements but drops replace directives.
+func readModFile(goModPath string) (*modfile.File, error) {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	f, err := modfile.Parse(goModPath, data, nil)
+	if err == nil {
+		return f, nil
+	}
+	slog.Warn("Ignoring replace directives of go.mod that could not be fully parsed", "path", goModPath, "error", err)
+	f, err = modfile.ParseLax(goModPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	return f, nil
+}
+
+// replacement returns the replace directive in the scanned go.mod that overrides the module
+// at the given version, or nil if there is none. Syft may report a replaced module either
+// under its original path or as the replacement module and version, so both are checked.
+func (s *Scanner) replacement(name, version string) *modfile.Replace {
+	for _, r := range s.replaces {
+		if r.Old.Path == name && (r.Old.Version == "" || r.Old.Version == version) {
+			return r
+		}
+		if r.New.Version != "" && r.New.Path == name && r.New.Version == version {
+			return r
+		}
+	}
+	return nil
+}
+
+// describeReplace summarizes a replace directive for reports
+func describeReplace(r *modfile.Replace) string {
+	if r.New.Version == "" {
+		return "replaced by local path " + r.New.Path
+	}
+	return fmt.Sprintf("replaced by %s %s", r.New.Path, r.New.Version)
+}
+
+// MainModule returns the module path of the most recently scanned project
+func (s *Scanner) MainModule() string {
+	return s.mainModule
 }
 
 // normalizeVersion normalizes a version by copying the prefix from the current version
-// if the target version is missing it
+// if the target version is missing it. When the current version is +incompatible, the module
+// has no go.mod and its path has no major version suffix, so a v2+ target is only available
+// as +incompatible too.
 func normalizeVersion(currentVersion, targetVersion string) string {
+	normalized := normalizeVersionPrefix(currentVersion, targetVersion)
+	if semver.Build(currentVersion) == incompatibleSuffix && semver.IsValid(normalized) &&
+		semver.Build(normalized) == "" && semver.Major(normalized) != "v0" && semver.Major(normalized) != "v1" {
+		normalized += incompatibleSuffix
+	}
+	return normalized
+}
+
+// incompatibleSuffix marks v2+ versions of modules published without a go.mod
+const incompatibleSuffix = "+incompatible"
+
+// normalizeVersionPrefix copies the prefix from the current version to the target version
+// if it's missing
+func normalizeVersionPrefix(currentVersion, targetVersion string) string {
+	// Targets that already carry the prefix are left alone
+	if semver.IsValid(targetVersion) {
+		return targetVersion
+	}
+
 	// Parse the current version as semver
 	if !semver.IsValid(currentVersion) {
 		// If current version is not valid semver, return target as-is
@@ -175,6 +1037,13 @@ func normalizeVersion(currentVersion, targetVersion string) string {
 
 // isValidGoVersion checks if a version string is valid for a Go module
 func isValidGoVersion(pkgName, version string) bool {
+	// +incompatible is only valid for v2+ of a module path without a major version suffix
+	if semver.Build(version) == incompatibleSuffix {
+		_, pathMajor, ok := module.SplitPathVersion(pkgName)
+		major := semver.Major(version)
+		return ok && pathMajor == "" && major != "v0" && major != "v1"
+	}
+
 	// Check if it's a valid semantic version
 	if semver.IsValid(version) {
 		return true
@@ -186,56 +1055,341 @@ func isValidGoVersion(pkgName, version string) bool {
 	return err == nil
 }
 
-// GetFixableUpdates extracts fixable Go module updates from scan results
+// GetFixableUpdates extracts fixable Go module updates from scan results.
+// Advisories matching the scanned module itself are excluded, see GetMainModuleUpdates,
+// as are advisories below the minimum severity unless the module is always fixed,
+// see SetMinSeverity and SetAlwaysFix. Updates to modules overridden by a replace
+// directive are kept but have Replace set.
 func (s *Scanner) GetFixableUpdates(matches match.Matches) []PackageUpdate {
+	if !s.includesFixState(vulnerability.FixStateFixed) {
+		return nil
+	}
+
+	var updates []PackageUpdate
+	// seen indexes updates by package and vulnerability when normalizing by CVE
+	seen := make(map[[2]string]int)
+
+	for m := range matches.Enumerate() {
+		// The module being scanned cannot be bumped in its own go.mod
+		if s.isMainModule(m.Package.Name) {
+			continue
+		}
+
+		update, ok := fixableUpdate(m, s.fixStrategy)
+		if !ok {
+			continue
+		}
+		if s.ignoredVulnerability(update.Name, update.VulnID, update.Aliases) {
+			continue
+		}
+		if s.requireInGoMod && !s.isRequired(update.Name, update.CurrentVersion) {
+			slog.Info("Skipping module not required by go.mod", "module", update.Name, "vulnerability", update.VulnID)
+			continue
+		}
+		s.applySeverityOverride(&update)
+		update.Direct = s.isDirect(update.Name)
+		if r := s.replacement(update.Name, update.CurrentVersion); r != nil {
+			update.Replace = describeReplace(r)
+		}
+		if s.normalizeByCVE {
+			preferCVE(&update)
+			key := [2]string{update.Name, update.VulnID}
+			if i, exists := seen[key]; exists {
+				mergeDuplicate(&updates[i], update)
+				continue
+			}
+			seen[key] = len(updates)
+		}
+		updates = append(updates, update)
+	}
+
+	// Escalation can lift a finding over the threshold, so it comes first
+	if s.escalate != nil {
+		s.escalate(updates)
+	}
+	if s.minSeverity != "" {
+		kept := updates[:0]
+		for _, update := range updates {
+			if meetsSeverity(update.SeverityForGating(), s.minSeverity) || s.isAlwaysFix(update.Name) {
+				kept = append(kept, update)
+			}
+		}
+		updates = kept
+	}
+
+	sortUpdates(updates)
+	return updates
+}
+
+// sortUpdates orders updates by package, vulnerability, and target version, and their aliases
+// alphabetically. Grype enumerates matches in no particular order, so this keeps the output
+// of identical scans identical.
+func sortUpdates(updates []PackageUpdate) {
+	for i := range updates {
+		sort.Strings(updates[i].Aliases)
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		a, b := updates[i], updates[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.VulnID != b.VulnID {
+			return a.VulnID < b.VulnID
+		}
+		return a.TargetVersion < b.TargetVersion
+	})
+}
+
+// preferCVE makes the CVE alias of an update its VulnID, keeping the original ID as an alias.
+// Grype only normalizes advisories whose CVE is in the same database record, so a GHSA can
+// still surface with its CVE listed only as a related vulnerability.
+func preferCVE(update *PackageUpdate) {
+	if strings.HasPrefix(update.VulnID, "CVE-") {
+		return
+	}
+	for i, alias := range update.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			update.Aliases[i] = update.VulnID
+			update.VulnID = alias
+			return
+		}
+	}
+}
+
+// mergeDuplicate folds another finding of the same vulnerability in the same package into
+// update, keeping the higher target version, the earlier publish date, and the aliases of both
+func mergeDuplicate(update *PackageUpdate, dup PackageUpdate) {
+	if semver.Compare(dup.TargetVersion, update.TargetVersion) > 0 {
+		update.TargetVersion = dup.TargetVersion
+		update.Explanation = dup.Explanation
+	}
+	for _, alias := range append(dup.Aliases, dup.VulnID) {
+		if alias != update.VulnID && !containsString(update.Aliases, alias) {
+			update.Aliases = append(update.Aliases, alias)
+		}
+	}
+	update.KnownExploited = update.KnownExploited || dup.KnownExploited
+	update.FixVersions = mergeFixVersions(update.FixVersions, dup.FixVersions)
+	if !dup.Published.IsZero() && (update.Published.IsZero() || dup.Published.Before(update.Published)) {
+		update.Published = dup.Published
+	}
+	if dup.EPSS > update.EPSS {
+		update.EPSS = dup.EPSS
+	}
+}
+
+// FilterSince returns the updates for vulnerabilities published at or after since. Updates
+// whose publish date is unknown are kept, unless strict is set.
+func FilterSince(updates []PackageUpdate, since time.Time, strict bool) []PackageUpdate {
+	var kept []PackageUpdate
+	for _, upd := range updates {
+		switch {
+		case upd.Published.IsZero() && strict:
+			slog.Info("Dropping vulnerability without a known publish date", "module", upd.Name, "vulnerability", upd.VulnID)
+		case !upd.Published.IsZero() && upd.Published.Before(since):
+			slog.Debug("Dropping vulnerability published before the cutoff",
+				"module", upd.Name, "vulnerability", upd.VulnID, "published", upd.Published.Format(time.DateOnly))
+		default:
+			kept = append(kept, upd)
+		}
+	}
+	return kept
+}
+
+// GetMainModuleUpdates returns fixable advisories whose package is the scanned module itself.
+// These can happen with forks or shared module paths and are reported separately
+// because grump cannot bump the module being scanned.
+func (s *Scanner) GetMainModuleUpdates(matches match.Matches) []PackageUpdate {
 	var updates []PackageUpdate
 
 	for m := range matches.Enumerate() {
-		// Filter: only Go modules with fixes
-		if m.Package.Type != syftPkg.GoModulePkg {
+		if !s.isMainModule(m.Package.Name) {
 			continue
 		}
 
-		// Check if vulnerability has a fix
-		if len(m.Vulnerability.Fix.Versions) == 0 || m.Vulnerability.Fix.State != vulnerability.FixStateFixed {
+		if update, ok := fixableUpdate(m, s.fixStrategy); ok {
+			updates = append(updates, update)
+		}
+	}
+
+	sortUpdates(updates)
+	return updates
+}
+
+// GetUnfixableVulnerabilities extracts Go module vulnerabilities that have no fix available
+func (s *Scanner) GetUnfixableVulnerabilities(matches match.Matches) []UnfixableVulnerability {
+	var unfixable []UnfixableVulnerability
+
+	for m := range matches.Enumerate() {
+		if m.Package.Type != syftPkg.GoModulePkg || s.isMainModule(m.Package.Name) {
 			continue
 		}
 
-		// Extract the suggested version
-		suggestedVersion := ""
-		if len(m.Vulnerability.Fix.Versions) > 0 {
-			suggestedVersion = m.Vulnerability.Fix.Versions[0]
+		if m.Vulnerability.Fix.State == vulnerability.FixStateFixed && len(m.Vulnerability.Fix.Versions) > 0 {
+			continue
+		}
+
+		var aliases []string
+		for _, related := range m.Vulnerability.RelatedVulnerabilities {
+			aliases = append(aliases, related.ID)
+		}
+		if s.ignoredVulnerability(m.Package.Name, m.Vulnerability.ID, aliases) {
+			continue
 		}
 
-		if suggestedVersion == "" {
+		fixState := m.Vulnerability.Fix.State
+		if fixState == "" {
+			fixState = vulnerability.FixStateUnknown
+		}
+		if !s.includesFixState(fixState) {
+			continue
+		}
+
+		unfixable = append(unfixable, UnfixableVulnerability{
+			Name:     m.Package.Name,
+			Version:  m.Package.Version,
+			VulnID:   m.Vulnerability.ID,
+			Severity: s.overrideSeverity(m.Package.Name, matchSeverity(m), m.Vulnerability.ID, aliases),
+			FixState: string(fixState),
+		})
+	}
+
+	// Like the fixable updates, keep the order stable across runs
+	sort.Slice(unfixable, func(i, j int) bool {
+		a, b := unfixable[i], unfixable[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.VulnID != b.VulnID {
+			return a.VulnID < b.VulnID
+		}
+		return a.Version < b.Version
+	})
+	return unfixable
+}
+
+// isMainModule reports whether the package name is the scanned module's own path
+func (s *Scanner) isMainModule(name string) bool {
+	return s.mainModule != "" && name == s.mainModule
+}
+
+// fixableUpdate converts a match into a PackageUpdate if it is a fixable Go module vulnerability
+func fixableUpdate(m match.Match, strategy FixVersionStrategy) (PackageUpdate, bool) {
+	// Filter: only Go modules with fixes
+	if m.Package.Type != syftPkg.GoModulePkg {
+		return PackageUpdate{}, false
+	}
+
+	// Check if vulnerability has a fix
+	if len(m.Vulnerability.Fix.Versions) == 0 || m.Vulnerability.Fix.State != vulnerability.FixStateFixed {
+		return PackageUpdate{}, false
+	}
+
+	// Pick the target among the fix versions that are valid for the module
+	fixVersions, advisoryForms := fixVersionCandidates(m.Package.Name, m.Package.Version, m.Vulnerability.Fix.Versions)
+	normalizedVersion := selectFixVersion(fixVersions, strategy)
+	if normalizedVersion == "" {
+		return PackageUpdate{}, false
+	}
+	if strategy == "" {
+		strategy = FixLowest
+	}
+
+	severity := matchSeverity(m)
+	update := PackageUpdate{
+		Name:              m.Package.Name,
+		CurrentVersion:    m.Package.Version,
+		TargetVersion:     normalizedVersion,
+		FixVersions:       fixVersions,
+		VulnID:            m.Vulnerability.ID,
+		Severity:          severity,
+		EffectiveSeverity: severity,
+		Confidence:        MatchConfidence(m.Details),
+		Explanation: &Explanation{
+			FixState:         string(m.Vulnerability.Fix.State),
+			AdvisoryVersions: m.Vulnerability.Fix.Versions,
+			Strategy:         strategy,
+			Selected:         normalizedVersion,
+			SelectedFrom:     advisoryForms[normalizedVersion],
+		},
+	}
+
+	for _, related := range m.Vulnerability.RelatedVulnerabilities {
+		if related.ID != m.Vulnerability.ID {
+			update.Aliases = append(update.Aliases, related.ID)
+		}
+	}
+	if handle, ok := m.Vulnerability.Internal.(*v6.VulnerabilityHandle); ok && handle.PublishedDate != nil {
+		update.Published = *handle.PublishedDate
+	}
+
+	if m.Vulnerability.Metadata != nil {
+		update.KnownExploited = len(m.Vulnerability.Metadata.KnownExploited) > 0
+		for _, epss := range m.Vulnerability.Metadata.EPSS {
+			if epss.EPSS > update.EPSS {
+				update.EPSS = epss.EPSS
+			}
+		}
+	}
+
+	return update, true
+}
+
+// fixVersionCandidates normalizes an advisory's fix versions against the current version and
+// returns the valid ones in ascending order, along with the advisory form of each
+func fixVersionCandidates(pkgName, currentVersion string, fixVersions []string) ([]string, map[string]string) {
+	var candidates []string
+	advisoryForms := make(map[string]string)
+	for _, candidate := range fixVersions {
+		if candidate == "" {
 			continue
 		}
 
 		// Normalize the version by copying prefix from current version
-		normalizedVersion := normalizeVersion(m.Package.Version, suggestedVersion)
+		normalized := normalizeVersion(currentVersion, candidate)
 
 		// Validate the version is parseable
-		if !isValidGoVersion(m.Package.Name, normalizedVersion) {
-			fmt.Printf("Requesting pin to %s.\n This is not a valid SemVer, so skipping version check.\n", normalizedVersion)
+		if !isValidGoVersion(pkgName, normalized) {
+			slog.Debug("Fix version is not valid semver, skipping version check", "module", pkgName, "version", normalized)
 			continue
 		}
 
-		// Extract severity from metadata
-		severity := "Unknown"
-		if m.Vulnerability.Metadata != nil {
-			severity = m.Vulnerability.Metadata.Severity
+		if !containsString(candidates, normalized) {
+			candidates = append(candidates, normalized)
+			advisoryForms[normalized] = candidate
 		}
+	}
+	semver.Sort(candidates)
+	return candidates, advisoryForms
+}
 
-		updates = append(updates, PackageUpdate{
-			Name:           m.Package.Name,
-			CurrentVersion: m.Package.Version,
-			TargetVersion:  normalizedVersion,
-			VulnID:         m.Vulnerability.ID,
-			Severity:       severity,
-		})
+// selectFixVersion returns the fix version to update to according to strategy from
+// candidates in ascending order, or "" if there are none
+func selectFixVersion(candidates []string, strategy FixVersionStrategy) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	if strategy == FixHighest {
+		return candidates[len(candidates)-1]
 	}
+	return candidates[0]
+}
 
-	return updates
+// matchSeverity extracts the severity label from a match's vulnerability metadata. An unknown
+// severity is derived from the CVSS base score when the metadata has one, see cvssSeverity.
+func matchSeverity(m match.Match) string {
+	metadata := m.Vulnerability.Metadata
+	if metadata == nil {
+		return "Unknown"
+	}
+	if metadata.Severity != "" && !strings.EqualFold(metadata.Severity, "Unknown") {
+		return metadata.Severity
+	}
+	if severity := cvssSeverity(metadata.Cvss); severity != "" {
+		slog.Debug("Derived severity from CVSS score", "vulnerability", m.Vulnerability.ID, "severity", severity)
+		return severity
+	}
+	return "Unknown"
 }
 
 // Close cleans up resources
@@ -0,0 +1,444 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v6 "github.com/anchore/grype/grype/db/v6"
+	"github.com/anchore/grype/grype/match"
+	"github.com/anchore/grype/grype/pkg"
+	"github.com/anchore/grype/grype/vulnerability"
+	syftPkg "github.com/anchore/syft/syft/pkg"
+)
+
+// goMatch returns a match of a Go module vulnerability fixed in the given versions
+func goMatch(name, version, id, severity string, fixes ...string) match.Match {
+	return match.Match{
+		Package: pkg.Package{Name: name, Version: version, Type: syftPkg.GoModulePkg},
+		Vulnerability: vulnerability.Vulnerability{
+			Reference: vulnerability.Reference{ID: id},
+			Fix:       vulnerability.Fix{Versions: fixes, State: vulnerability.FixStateFixed},
+			Metadata:  &vulnerability.Metadata{Severity: severity},
+		},
+	}
+}
+
+func TestSeverityOverrideChangesGating(t *testing.T) {
+	s := &Scanner{}
+	if err := s.SetSeverityOverrides(map[string]string{"GHSA-crit": "low"}); err != nil {
+		t.Fatal(err)
+	}
+	matches := match.NewMatches(goMatch("example.com/a", "v1.0.0", "GHSA-crit", "Critical", "1.0.1"))
+
+	updates := s.GetFixableUpdates(matches)
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1", len(updates))
+	}
+	upd := updates[0]
+	if upd.Severity != "Low" || upd.EffectiveSeverity != "Low" || upd.SeverityForGating() != "Low" {
+		t.Errorf("severity = %q, effective = %q, gating = %q; want Low for all",
+			upd.Severity, upd.EffectiveSeverity, upd.SeverityForGating())
+	}
+
+	// The threshold sees the overridden severity too
+	if err := s.SetMinSeverity("medium"); err != nil {
+		t.Fatal(err)
+	}
+	if updates := s.GetFixableUpdates(matches); len(updates) != 0 {
+		t.Errorf("got %d updates above -min-severity medium, want 0", len(updates))
+	}
+}
+
+func TestSeverityOverrideKeepsEscalation(t *testing.T) {
+	s := &Scanner{}
+	if err := s.SetSeverityOverrides(map[string]string{"CVE-2024-1": "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	upd := PackageUpdate{Name: "example.com/a", VulnID: "CVE-2024-1", Severity: "Low", EffectiveSeverity: "Critical"}
+
+	s.applySeverityOverride(&upd)
+	if upd.Severity != "Medium" || upd.EffectiveSeverity != "Critical" {
+		t.Errorf("severity = %q, effective = %q; want Medium, Critical", upd.Severity, upd.EffectiveSeverity)
+	}
+}
+
+func TestEscalationBeforeMinSeverity(t *testing.T) {
+	s := &Scanner{}
+	if err := s.SetMinSeverity("high"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetEscalation(func(updates []PackageUpdate) {
+		for i := range updates {
+			if updates[i].VulnID == "CVE-2024-kev" {
+				updates[i].EffectiveSeverity = "Critical"
+			}
+		}
+	})
+	matches := match.NewMatches(
+		goMatch("example.com/a", "v1.0.0", "CVE-2024-kev", "Low", "1.0.1"),
+		goMatch("example.com/b", "v1.0.0", "CVE-2024-low", "Low", "1.0.1"),
+	)
+
+	updates := s.GetFixableUpdates(matches)
+	if len(updates) != 1 || updates[0].VulnID != "CVE-2024-kev" {
+		t.Fatalf("got %+v, want only the escalated CVE-2024-kev", updates)
+	}
+	if updates[0].Severity != "Low" || updates[0].SeverityForGating() != "Critical" {
+		t.Errorf("severity = %q, gating = %q; want Low, Critical", updates[0].Severity, updates[0].SeverityForGating())
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		current, target, want string
+	}{
+		{"v1.2.3", "1.2.4", "v1.2.4"},
+		{"v1.2.3", "v1.2.4", "v1.2.4"},
+		{"v0.0.0-20220127200216-cd36cc0744dd", "0.1.0", "v0.1.0"},
+		// Modules without a go.mod stay +incompatible above v1
+		{"v20.10.7+incompatible", "20.10.11", "v20.10.11+incompatible"},
+		{"v20.10.7+incompatible", "v20.10.11", "v20.10.11+incompatible"},
+		{"v20.10.7+incompatible", "v20.10.11+incompatible", "v20.10.11+incompatible"},
+		{"v3.2.0+incompatible", "4.0.0", "v4.0.0+incompatible"},
+		// v0 and v1 versions are never +incompatible
+		{"v20.10.7+incompatible", "1.13.1", "v1.13.1"},
+		{"v2.1.0", "2.2.0", "v2.2.0"},
+	}
+	for _, tt := range tests {
+		if got := normalizeVersion(tt.current, tt.target); got != tt.want {
+			t.Errorf("normalizeVersion(%q, %q) = %q, want %q", tt.current, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidGoVersion(t *testing.T) {
+	tests := []struct {
+		path, version string
+		want          bool
+	}{
+		{"github.com/docker/docker", "v20.10.11+incompatible", true},
+		{"github.com/docker/docker", "v20.10.11", true},
+		{"golang.org/x/net", "v0.0.0-20220127200216-cd36cc0744dd", true},
+		{"golang.org/x/net", "0.7.0", false},
+		// +incompatible needs a v2+ version and a path without a major version suffix
+		{"github.com/docker/docker", "v1.13.1+incompatible", false},
+		{"github.com/docker/docker/v24", "v24.0.7+incompatible", false},
+		{"gopkg.in/yaml.v2", "v2.4.0+incompatible", false},
+	}
+	for _, tt := range tests {
+		if got := isValidGoVersion(tt.path, tt.version); got != tt.want {
+			t.Errorf("isValidGoVersion(%q, %q) = %v, want %v", tt.path, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestFixVersionCandidatesIncompatible(t *testing.T) {
+	tests := []struct {
+		path, current string
+		fixes         []string
+		want          []string
+	}{
+		{
+			path:    "github.com/docker/docker",
+			current: "v20.10.7+incompatible",
+			fixes:   []string{"24.0.7", "20.10.11"},
+			want:    []string{"v20.10.11+incompatible", "v24.0.7+incompatible"},
+		},
+		{
+			// A module with a major version suffix has a go.mod, so nothing is +incompatible
+			path:    "github.com/docker/docker/v24",
+			current: "v24.0.6",
+			fixes:   []string{"24.0.7"},
+			want:    []string{"v24.0.7"},
+		},
+	}
+	for _, tt := range tests {
+		got, forms := fixVersionCandidates(tt.path, tt.current, tt.fixes)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("fixVersionCandidates(%q, %q, %v) = %v, want %v", tt.path, tt.current, tt.fixes, got, tt.want)
+		}
+		for _, version := range got {
+			if forms[version] == "" {
+				t.Errorf("no advisory form recorded for %s", version)
+			}
+		}
+	}
+
+	// The chosen fix is applied to an +incompatible module in its +incompatible form
+	update, ok := fixableUpdate(goMatch("github.com/docker/docker", "v20.10.7+incompatible", "GHSA-docker", "High", "20.10.11"), FixLowest)
+	if !ok || update.TargetVersion != "v20.10.11+incompatible" {
+		t.Errorf("fixableUpdate target = %q (ok %v), want v20.10.11+incompatible", update.TargetVersion, ok)
+	}
+}
+
+// writeGoMod writes a go.mod with the given content to a temporary directory and returns its path
+func writeGoMod(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMainModuleExcludedFromUpdates(t *testing.T) {
+	s := &Scanner{}
+	goMod := writeGoMod(t, "module example.com/fork\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n")
+	if err := s.ScanModuleGraph(goMod); err != nil {
+		t.Fatal(err)
+	}
+	matches := match.NewMatches(
+		goMatch("example.com/fork", "v1.0.0", "GHSA-self", "High", "1.0.1"),
+		goMatch("example.com/dep", "v1.0.0", "GHSA-dep", "High", "1.0.1"),
+	)
+
+	updates := s.GetFixableUpdates(matches)
+	if len(updates) != 1 || updates[0].Name != "example.com/dep" {
+		t.Errorf("fixable updates = %+v, want only example.com/dep", updates)
+	}
+	self := s.GetMainModuleUpdates(matches)
+	if len(self) != 1 || self[0].Name != "example.com/fork" || self[0].VulnID != "GHSA-self" {
+		t.Errorf("main module updates = %+v, want GHSA-self for example.com/fork", self)
+	}
+	if unfixable := s.GetUnfixableVulnerabilities(match.NewMatches(
+		goMatch("example.com/fork", "v1.0.0", "GHSA-nofix", "High"),
+	)); len(unfixable) != 0 {
+		t.Errorf("unfixable = %+v, want the main module excluded", unfixable)
+	}
+}
+
+// withAliases returns m with the given IDs listed as related vulnerabilities
+func withAliases(m match.Match, ids ...string) match.Match {
+	for _, id := range ids {
+		m.Vulnerability.RelatedVulnerabilities = append(m.Vulnerability.RelatedVulnerabilities, vulnerability.Reference{ID: id})
+	}
+	return m
+}
+
+func TestNormalizeByCVECounts(t *testing.T) {
+	// The same vulnerability reported under its GHSA and its CVE
+	matches := match.NewMatches(
+		withAliases(goMatch("example.com/a", "v1.0.0", "GHSA-aaaa-bbbb-cccc", "High", "1.0.1"), "CVE-2024-1"),
+		withAliases(goMatch("example.com/a", "v1.0.0", "CVE-2024-1", "High", "1.0.1"), "GHSA-aaaa-bbbb-cccc"),
+		goMatch("example.com/b", "v1.0.0", "GHSA-only-ghsa-id", "High", "1.0.1"),
+	)
+
+	if updates := (&Scanner{}).GetFixableUpdates(matches); len(updates) != 3 {
+		t.Errorf("without normalization got %d updates, want 3", len(updates))
+	}
+
+	updates := (&Scanner{normalizeByCVE: true}).GetFixableUpdates(matches)
+	if len(updates) != 2 {
+		t.Fatalf("with normalization got %d updates, want 2: %+v", len(updates), updates)
+	}
+	if updates[0].VulnID != "CVE-2024-1" || updates[1].VulnID != "GHSA-only-ghsa-id" {
+		t.Errorf("vulnerabilities = %s, %s; want CVE-2024-1, GHSA-only-ghsa-id", updates[0].VulnID, updates[1].VulnID)
+	}
+
+	// Coalescing still folds every finding of a package into one update either way
+	if coalesced := CoalesceUpdates((&Scanner{}).GetFixableUpdates(matches)); len(coalesced) != 2 {
+		t.Errorf("coalesced without normalization got %d updates, want 2", len(coalesced))
+	}
+}
+
+func TestNormalizeByCVEMergesDuplicateGHSAs(t *testing.T) {
+	s := &Scanner{normalizeByCVE: true}
+	// Two GHSAs for the same CVE with different fix versions
+	matches := match.NewMatches(
+		withAliases(goMatch("example.com/a", "v1.0.0", "GHSA-1111-1111-1111", "Medium", "1.0.1"), "CVE-2024-1"),
+		withAliases(goMatch("example.com/a", "v1.0.0", "GHSA-2222-2222-2222", "Medium", "1.0.2"), "CVE-2024-1"),
+	)
+
+	updates := s.GetFixableUpdates(matches)
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1: %+v", len(updates), updates)
+	}
+	upd := updates[0]
+	if upd.VulnID != "CVE-2024-1" {
+		t.Errorf("VulnID = %q, want CVE-2024-1", upd.VulnID)
+	}
+	if upd.TargetVersion != "v1.0.2" {
+		t.Errorf("TargetVersion = %q, want the higher fix v1.0.2", upd.TargetVersion)
+	}
+	if strings.Join(upd.Aliases, ",") != "GHSA-1111-1111-1111,GHSA-2222-2222-2222" {
+		t.Errorf("Aliases = %v, want both GHSAs", upd.Aliases)
+	}
+	if strings.Join(upd.FixVersions, ",") != "v1.0.1,v1.0.2" {
+		t.Errorf("FixVersions = %v, want v1.0.1,v1.0.2", upd.FixVersions)
+	}
+}
+
+func TestReplacedModulesAreMarked(t *testing.T) {
+	s := &Scanner{}
+	goMod := writeGoMod(t, `module example.com/app
+
+go 1.22
+
+require (
+	example.com/local v1.0.0
+	example.com/forked v1.0.0
+	example.com/pinned v1.0.0
+	example.com/plain v1.0.0
+)
+
+replace example.com/local => ../local
+
+replace example.com/forked => example.com/fork v1.5.0
+
+replace example.com/pinned v0.9.0 => example.com/pinned v0.9.1
+`)
+	if err := s.ScanModuleGraph(goMod); err != nil {
+		t.Fatal(err)
+	}
+	matches := match.NewMatches(
+		goMatch("example.com/local", "v1.0.0", "GHSA-local", "High", "1.0.1"),
+		// Syft may report a version replace under the replacement module
+		goMatch("example.com/fork", "v1.5.0", "GHSA-fork", "High", "1.5.1"),
+		// The replace only applies to another version
+		goMatch("example.com/pinned", "v1.0.0", "GHSA-pinned", "High", "1.0.1"),
+		goMatch("example.com/plain", "v1.0.0", "GHSA-plain", "High", "1.0.1"),
+	)
+
+	want := map[string]string{
+		"example.com/local":  "replaced by local path ../local",
+		"example.com/fork":   "replaced by example.com/fork v1.5.0",
+		"example.com/pinned": "",
+		"example.com/plain":  "",
+	}
+	updates := s.GetFixableUpdates(matches)
+	if len(updates) != len(want) {
+		t.Fatalf("got %d updates, want %d", len(updates), len(want))
+	}
+	for _, upd := range updates {
+		if upd.Replace != want[upd.Name] {
+			t.Errorf("%s: Replace = %q, want %q", upd.Name, upd.Replace, want[upd.Name])
+		}
+	}
+}
+
+func TestCoalesceUpdates(t *testing.T) {
+	updates := []PackageUpdate{
+		{Name: "example.com/a", TargetVersion: "v1.0.1", VulnID: "GHSA-1", Severity: "Medium", EffectiveSeverity: "Medium",
+			Confidence: ConfidenceHigh, FixVersions: []string{"v1.0.1"}},
+		{Name: "example.com/b", TargetVersion: "v2.0.1", VulnID: "GHSA-2", Severity: "Low"},
+		{Name: "example.com/a", TargetVersion: "v1.2.0", VulnID: "GHSA-3", Severity: "High", EffectiveSeverity: "Critical",
+			Confidence: ConfidenceLow, FixVersions: []string{"v1.2.0"}, Aliases: []string{"CVE-2024-3"}, KnownExploited: true},
+	}
+
+	coalesced := CoalesceUpdates(updates)
+	if len(coalesced) != 2 || coalesced[0].Name != "example.com/a" || coalesced[1].Name != "example.com/b" {
+		t.Fatalf("got %+v, want one update each for example.com/a and example.com/b in order", coalesced)
+	}
+	a := coalesced[0]
+	if a.TargetVersion != "v1.2.0" {
+		t.Errorf("TargetVersion = %q, want the highest v1.2.0", a.TargetVersion)
+	}
+	if strings.Join(a.VulnIDs, ",") != "GHSA-1,GHSA-3" {
+		t.Errorf("VulnIDs = %v, want GHSA-1,GHSA-3", a.VulnIDs)
+	}
+	if a.Severity != "High" || a.SeverityForGating() != "Critical" || a.Confidence != ConfidenceHigh || !a.KnownExploited {
+		t.Errorf("severity = %q, gating = %q, confidence = %q, known exploited = %v; want High, Critical, high, true",
+			a.Severity, a.SeverityForGating(), a.Confidence, a.KnownExploited)
+	}
+	if strings.Join(a.FixVersions, ",") != "v1.0.1,v1.2.0" || strings.Join(a.Aliases, ",") != "CVE-2024-3" {
+		t.Errorf("FixVersions = %v, Aliases = %v; want v1.0.1,v1.2.0 and CVE-2024-3", a.FixVersions, a.Aliases)
+	}
+
+	// The input is left alone
+	if updates[0].TargetVersion != "v1.0.1" || updates[0].VulnIDs != nil {
+		t.Errorf("input modified: %+v", updates[0])
+	}
+}
+
+func TestFilterSince(t *testing.T) {
+	published := func(date string) time.Time {
+		d, _ := time.Parse(time.DateOnly, date)
+		return d
+	}
+	recent := goMatch("example.com/recent", "v1.0.0", "GHSA-recent", "High", "1.0.1")
+	recent.Vulnerability.Internal = &v6.VulnerabilityHandle{PublishedDate: ptr(published("2024-06-01"))}
+	old := goMatch("example.com/old", "v1.0.0", "GHSA-old", "High", "1.0.1")
+	old.Vulnerability.Internal = &v6.VulnerabilityHandle{PublishedDate: ptr(published("2023-01-01"))}
+	undated := goMatch("example.com/undated", "v1.0.0", "GHSA-undated", "High", "1.0.1")
+
+	updates := (&Scanner{}).GetFixableUpdates(match.NewMatches(recent, old, undated))
+	if len(updates) != 3 || !updates[1].Published.Equal(published("2024-06-01")) {
+		t.Fatalf("got %+v, want three updates with publish dates from the database", updates)
+	}
+
+	since := published("2024-01-01")
+	names := func(updates []PackageUpdate) string {
+		var names []string
+		for _, upd := range updates {
+			names = append(names, upd.Name)
+		}
+		return strings.Join(names, ",")
+	}
+	if got := names(FilterSince(updates, since, false)); got != "example.com/recent,example.com/undated" {
+		t.Errorf("FilterSince = %s, want the recent and undated updates", got)
+	}
+	if got := names(FilterSince(updates, since, true)); got != "example.com/recent" {
+		t.Errorf("strict FilterSince = %s, want only the recent update", got)
+	}
+}
+
+func TestSetExcludePaths(t *testing.T) {
+	s := &Scanner{}
+	if err := s.SetExcludePaths([]string{"vendor/**", "/build/**", "./dist/**", "**/testdata/**", "*/fixtures/*"}); err != nil {
+		t.Fatal(err)
+	}
+	// Syft only accepts patterns rooted with "./", "*/", or "**/"
+	want := "./vendor/**,./build/**,./dist/**,**/testdata/**,*/fixtures/*"
+	if got := strings.Join(s.excludePaths, ","); got != want {
+		t.Errorf("excludePaths = %s, want %s", got, want)
+	}
+	if err := s.SetExcludePaths([]string{"vendor/["}); err == nil {
+		t.Error("SetExcludePaths accepted a malformed pattern")
+	}
+}
+
+func TestExcludePathsSkipsTestdataAndVendor(t *testing.T) {
+	dir := t.TempDir()
+	for file, name := range map[string]string{
+		"requirements.txt":                          "kept",
+		"testdata/requirements.txt":                 "fixture",
+		"internal/parser/testdata/requirements.txt": "nested-fixture",
+		"vendor/requirements.txt":                   "vendored",
+	} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"==1.0.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Scanner{}
+	if err := s.SetExcludePaths([]string{"**/testdata/**", "vendor/**"}); err != nil {
+		t.Fatal(err)
+	}
+	// The second catalog checks that the patterns survive the first
+	for i := 0; i < 2; i++ {
+		sbomResult, err := s.catalogDir(context.Background(), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for p := range sbomResult.Artifacts.Packages.Enumerate() {
+			names = append(names, p.Name)
+		}
+		if got := strings.Join(names, ","); got != "kept" {
+			t.Errorf("catalog %d found %q, want only the package outside testdata and vendor", i+1, got)
+		}
+	}
+}
+
+// ptr returns a pointer to v
+func ptr[T any](v T) *T {
+	return &v
+}
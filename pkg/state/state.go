@@ -0,0 +1,189 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/divolgin/grump/pkg/scanner"
+)
+
+// Finding identifies a single vulnerability in a single module.
+// Findings are keyed by module and vulnerability ID only, so a version bump that
+// leaves the module vulnerable does not register as a new finding.
+type Finding struct {
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	VulnID   string `json:"vulnerability_id"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// key returns the identity of the finding
+func (f Finding) key() string {
+	return f.Package + "@" + f.VulnID
+}
+
+// State is the set of findings recorded by the previous run
+type State struct {
+	// DBSchemaVersion is the vulnerability database schema the findings were matched against
+	DBSchemaVersion string    `json:"db_schema_version"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Findings        []Finding `json:"findings"`
+	// Resolved are findings that were present in an earlier run and have since disappeared.
+	// They are kept so that a finding coming back can be reported as reappeared rather than new.
+	Resolved []Finding `json:"resolved,omitempty"`
+}
+
+// Delta is the change in findings since the previous run
+type Delta struct {
+	New        []Finding `json:"new"`
+	Fixed      []Finding `json:"fixed"`
+	Reappeared []Finding `json:"reappeared"`
+	// Reset is set when there was no usable previous state, in which case every finding is new
+	Reset bool `json:"reset,omitempty"`
+}
+
+// Empty reports whether nothing changed since the previous run
+func (d *Delta) Empty() bool {
+	return len(d.New) == 0 && len(d.Fixed) == 0 && len(d.Reappeared) == 0
+}
+
+// Introduced returns the findings that were not present in the previous run, new or reappeared
+func (d *Delta) Introduced() []Finding {
+	return append(append([]Finding{}, d.New...), d.Reappeared...)
+}
+
+// Load reads the state file at path. A missing file returns nil state and no error.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return &st, nil
+}
+
+// Save writes the state to path
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// FromScan collects the findings of a scan, both fixable and unfixable
+func FromScan(updates []scanner.PackageUpdate, unfixable []scanner.UnfixableVulnerability) []Finding {
+	findings := make([]Finding, 0, len(updates)+len(unfixable))
+	for _, upd := range updates {
+		findings = append(findings, Finding{
+			Package:  upd.Name,
+			Version:  upd.CurrentVersion,
+			VulnID:   upd.VulnID,
+			Severity: upd.SeverityForGating(),
+		})
+	}
+	for _, vuln := range unfixable {
+		findings = append(findings, Finding{
+			Package:  vuln.Name,
+			Version:  vuln.Version,
+			VulnID:   vuln.VulnID,
+			Severity: vuln.Severity,
+		})
+	}
+	return findings
+}
+
+// Advance compares the current findings with the previous state and returns the delta
+// along with the state to record for the next run. A nil previous state, or one matched
+// against a different database schema, is treated as a full reset.
+func Advance(prev *State, findings []Finding, dbSchemaVersion string) (*Delta, *State) {
+	current := dedupe(findings)
+	next := &State{
+		DBSchemaVersion: dbSchemaVersion,
+		UpdatedAt:       time.Now().UTC(),
+		Findings:        current,
+	}
+
+	if prev == nil || prev.DBSchemaVersion != dbSchemaVersion {
+		return &Delta{New: current, Reset: true}, next
+	}
+
+	previous := index(prev.Findings)
+	resolved := index(prev.Resolved)
+	seen := make(map[string]bool, len(current))
+
+	delta := &Delta{}
+	for _, f := range current {
+		seen[f.key()] = true
+		switch {
+		case previous[f.key()]:
+		case resolved[f.key()]:
+			delta.Reappeared = append(delta.Reappeared, f)
+		default:
+			delta.New = append(delta.New, f)
+		}
+	}
+
+	for _, f := range prev.Findings {
+		if !seen[f.key()] {
+			delta.Fixed = append(delta.Fixed, f)
+			next.Resolved = append(next.Resolved, f)
+		}
+	}
+	for _, f := range prev.Resolved {
+		if !seen[f.key()] && !previous[f.key()] {
+			next.Resolved = append(next.Resolved, f)
+		}
+	}
+	sortFindings(next.Resolved)
+
+	return delta, next
+}
+
+// dedupe removes repeated findings and sorts the rest for stable state files
+func dedupe(findings []Finding) []Finding {
+	seen := make(map[string]bool, len(findings))
+	result := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		if seen[f.key()] {
+			continue
+		}
+		seen[f.key()] = true
+		result = append(result, f)
+	}
+	sortFindings(result)
+	return result
+}
+
+// index returns the set of finding keys
+func index(findings []Finding) map[string]bool {
+	keys := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		keys[f.key()] = true
+	}
+	return keys
+}
+
+// sortFindings orders findings by package, then vulnerability ID
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Package != findings[j].Package {
+			return findings[i].Package < findings[j].Package
+		}
+		return findings[i].VulnID < findings[j].VulnID
+	})
+}
@@ -0,0 +1,54 @@
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// scpURL matches the scp-like syntax git accepts for SSH remotes, such as git@github.com:org/repo
+var scpURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// IsRemoteURL reports whether arg names a remote git repository rather than a local path
+func IsRemoteURL(arg string) bool {
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		if strings.HasPrefix(arg, scheme) {
+			return true
+		}
+	}
+	return scpURL.MatchString(arg)
+}
+
+// RedactURL hides the password or token in a URL so it can be logged and reported
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); !ok && strings.HasPrefix(u.Scheme, "http") {
+		// A token given as the user name is just as secret; SSH user names are not
+		u.User = url.User("xxxxx")
+	}
+	return u.Redacted()
+}
+
+// Clone makes a shallow clone of the default branch of the repository at rawURL into dir.
+// Credentials come from git's own configuration, such as credential helpers, GIT_ASKPASS, or
+// the SSH agent. Unless GIT_TERMINAL_PROMPT is set, git fails instead of prompting for them.
+func Clone(rawURL, dir string) error {
+	cmd := exec.Command("git", "clone", "--depth", "1", "--quiet", "--", rawURL, dir)
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("GIT_TERMINAL_PROMPT"); !ok {
+		cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone %s: %w: %s", RedactURL(rawURL), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
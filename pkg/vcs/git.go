@@ -0,0 +1,98 @@
+package vcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/divolgin/grump/pkg/patcher"
+)
+
+// ErrNothingToCommit is returned by CommitChanges when the paths have no changes to commit
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// git runs a git command in dir and returns its standard output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// CheckClean returns an error if the work tree containing dir has uncommitted changes to
+// tracked files. Untracked files are ignored because they are never committed.
+func CheckClean(dir string) error {
+	status, err := git(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return err
+	}
+	if status = strings.TrimSpace(status); status != "" {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them first:\n%s", status)
+	}
+	return nil
+}
+
+// CreateBranch creates a branch at the current commit and checks it out
+func CreateBranch(dir, name string) error {
+	if _, err := git(dir, "check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("invalid branch name %q: %w", name, err)
+	}
+	if _, err := git(dir, "checkout", "-b", name); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	return nil
+}
+
+// CommitChanges stages the given paths, relative to dir, and commits them with message.
+// It returns ErrNothingToCommit if none of the paths changed.
+func CommitChanges(dir, message string, paths ...string) error {
+	args := append([]string{"add", "--"}, paths...)
+	if _, err := git(dir, args...); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+	staged, err := git(dir, "diff", "--cached", "--name-only")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(staged) == "" {
+		return ErrNothingToCommit
+	}
+	if _, err := git(dir, "commit", "--quiet", "--message", message); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return nil
+}
+
+// CommitMessage describes the applied updates, listing the vulnerabilities each one fixes.
+// It returns "" when no update was applied.
+func CommitMessage(results []patcher.UpdateResult) string {
+	var lines []string
+	for _, result := range results {
+		if !result.Success || result.RolledBack {
+			continue
+		}
+		upd := result.Update
+		vulnIDs := upd.VulnIDs
+		if len(vulnIDs) == 0 {
+			vulnIDs = []string{upd.VulnID}
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s -> %s (%s)",
+			upd.Name, upd.CurrentVersion, upd.TargetVersion, strings.Join(vulnIDs, ", ")))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	subject := "Update 1 vulnerable Go module"
+	if len(lines) > 1 {
+		subject = fmt.Sprintf("Update %d vulnerable Go modules", len(lines))
+	}
+	return subject + "\n\n" + strings.Join(lines, "\n") + "\n"
+}
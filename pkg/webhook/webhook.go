@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, hex encoded with a "sha256="
+// prefix, when a secret is configured
+const SignatureHeader = "X-Grump-Signature"
+
+// Backoff between delivery attempts: the first retry waits initialDelay, and each following
+// one waits twice as long, up to maxDelay
+const (
+	initialDelay = 2 * time.Second
+	maxDelay     = 30 * time.Second
+)
+
+// Config describes where and how a report is delivered
+type Config struct {
+	// URL is the endpoint the report is POSTed to
+	URL string
+	// Headers are added to the request, e.g. for authentication
+	Headers http.Header
+	// Secret signs the body in SignatureHeader when set
+	Secret string
+	// Retries is how many times delivery is retried after a network error or a 5xx response
+	Retries int
+	// Timeout limits each attempt; 0 uses 30 seconds
+	Timeout time.Duration
+}
+
+// ParseHeader parses a header given as "Name: value"
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q: must be \"Name: value\"", header)
+	}
+	return textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// Sign returns the SignatureHeader value of body for secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send POSTs the JSON body to the configured URL. Network errors and 5xx responses are
+// retried with exponential backoff; other non-2xx responses fail immediately.
+func Send(ctx context.Context, cfg Config, body []byte) error {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	delay := initialDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := post(ctx, client, cfg, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt > cfg.Retries {
+			return err
+		}
+
+		slog.Warn("Webhook delivery failed, retrying",
+			"attempt", attempt, "retries", cfg.Retries, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying
+func post(ctx context.Context, client *http.Client, cfg Config, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	for name, values := range cfg.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(cfg.Secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	return resp.StatusCode >= 500, fmt.Errorf("failed to deliver webhook: unexpected status %s", resp.Status)
+}